@@ -0,0 +1,50 @@
+// gobatis-gen 根据mapper文件生成带类型的dao函数，通常通过 go:generate 调用：
+//
+//	//go:generate go run github.com/non1996/go-batis/cmd/gobatis-gen -mappers ./mapper -pkg dao -out mapper_gen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/non1996/go-batis/gen"
+	"github.com/non1996/go-batis/sql"
+)
+
+func main() {
+	var (
+		mappers = flag.String("mappers", ".", "mapper文件所在目录")
+		pkg     = flag.String("pkg", os.Getenv("GOPACKAGE"), "生成代码的包名，默认为 go:generate 所在的包")
+		out     = flag.String("out", "mapper_gen.go", "输出文件")
+		imports = flag.String("imports", "", "结果类型所需的额外import，以逗号分隔")
+	)
+	flag.Parse()
+
+	if err := run(*mappers, *pkg, *out, *imports); err != nil {
+		fmt.Fprintln(os.Stderr, "gobatis-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, pkg, out, imports string) error {
+	if pkg == "" {
+		return fmt.Errorf("missing -pkg")
+	}
+
+	mappers, err := sql.LoadMappers(dir)
+	if err != nil {
+		return err
+	}
+
+	opts := gen.Options{Package: pkg}
+	if imports != "" {
+		opts.Imports = strings.Split(imports, ",")
+	}
+	src, err := gen.Generate(mappers, opts)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(out, src, 0o644)
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/non1996/go-batis/sql"
+)
+
+var formats = map[string]sql.Format{
+	"none":    sql.FormatNone,
+	"compact": sql.FormatCompact,
+	"pretty":  sql.FormatPretty,
+}
+
+// render 以给定参数渲染sql定义，输出sql及按顺序排列的参数值
+func render(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	dir := mappersFlag(fs)
+	paramsFile := fs.String("params", "", "json格式的参数文件")
+	format := fs.String("format", "pretty", "sql格式：none/compact/pretty")
+	trace := fs.Bool("trace", false, "输出求值追踪树")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("render: expect exactly one sql id")
+	}
+	id := positional[0]
+
+	mappers, err := sql.LoadMappers(*dir)
+	if err != nil {
+		return err
+	}
+	params, err := loadParams(*paramsFile)
+	if err != nil {
+		return err
+	}
+	f, exist := formats[*format]
+	if !exist {
+		return fmt.Errorf("render: unknown format %s", *format)
+	}
+
+	collection, err := mappers.Collection()
+	if err != nil {
+		return err
+	}
+	if _, exist = collection[id]; !exist {
+		return fmt.Errorf("render: missing sql %s", id)
+	}
+	ctx := sql.NewContext().
+		WithCollection(collection).
+		WithParams(params).
+		WithFormat(f)
+	if *trace {
+		ctx.WithTrace()
+	}
+
+	statement, err := ctx.Evaluate(collection[id])
+	if ctx.Trace() != nil {
+		fmt.Fprintln(out, ctx.Trace().String())
+	}
+	if err != nil {
+		return err
+	}
+	stmt, values, err := ctx.Resolve(statement)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, stmt)
+	fmt.Fprintln(out)
+	for i, v := range values {
+		fmt.Fprintf(out, "%d\t%#v\n", i+1, v)
+	}
+	return nil
+}
+
+func loadParams(file string) (sql.MapParameters, error) {
+	params := sql.MapParameters{}
+	if file == "" {
+		return params, nil
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(b, &params); err != nil {
+		return nil, fmt.Errorf("params %s: %w", file, err)
+	}
+	return params, nil
+}
+
+// validate 检查mapper目录：文件能否解析、id是否重复、引用是否存在或循环
+func validate(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	dir := mappersFlag(fs)
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	mappers, err := sql.LoadMappers(*dir)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	defined := map[string]string{}
+	for _, m := range mappers {
+		for _, s := range m.Statements {
+			if file, exist := defined[s.ID]; exist {
+				problems = append(problems, fmt.Sprintf("%s: duplicate id %s, first defined in %s", m.File, s.ID, file))
+				continue
+			}
+			defined[s.ID] = m.File
+		}
+	}
+
+	// id重复时已在上面报告，不再检查引用
+	collection, err := mappers.Collection()
+	if err == nil {
+		for _, s := range mappers.Statements() {
+			if _, err = collection.Parameters(s.ID); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", s.ID, err))
+			}
+		}
+	}
+
+	for _, p := range problems {
+		fmt.Fprintln(out, p)
+	}
+	if len(problems) != 0 {
+		return fmt.Errorf("validate: %d problem(s) found", len(problems))
+	}
+	fmt.Fprintf(out, "ok: %d mapper(s), %d sql definition(s)\n", len(mappers), len(collection))
+	return nil
+}
+
+// list 输出所有sql id及其引用的参数
+func list(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	dir := mappersFlag(fs)
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	mappers, err := sql.LoadMappers(*dir)
+	if err != nil {
+		return err
+	}
+
+	collection, err := mappers.Collection()
+	if err != nil {
+		return err
+	}
+	for _, s := range mappers.Statements() {
+		set, err := collection.Parameters(s.ID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.ID, err)
+		}
+		fmt.Fprintf(out, "%s\t%s\n", s.ID, s.Kind)
+		printNames(out, "params", set.Params())
+		printNames(out, "props", set.Props())
+		printNames(out, "tested", set.Tested())
+	}
+	return nil
+}
+
+func printNames(out io.Writer, label string, names []string) {
+	if len(names) != 0 {
+		fmt.Fprintf(out, "\t%s: %s\n", label, strings.Join(names, ", "))
+	}
+}
+
+// graph 输出include依赖图，-dot 输出graphviz格式
+func graph(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("graph", flag.ContinueOnError)
+	dir := mappersFlag(fs)
+	dot := fs.Bool("dot", false, "输出graphviz dot格式")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	mappers, err := sql.LoadMappers(*dir)
+	if err != nil {
+		return err
+	}
+
+	collection, err := mappers.Collection()
+	if err != nil {
+		return err
+	}
+	edges := map[string][]string{}
+	for _, s := range mappers.Statements() {
+		set, err := collection.Parameters(s.ID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.ID, err)
+		}
+		for _, include := range set.Includes {
+			if include.From != s.ID {
+				continue
+			}
+			target := include.ID
+			if include.IDFromProp {
+				target = "${" + include.ID + "}"
+			}
+			edges[s.ID] = appendUnique(edges[s.ID], target)
+		}
+	}
+
+	ids := make([]string, 0, len(collection))
+	for id := range collection {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	if *dot {
+		fmt.Fprintln(out, "digraph gobatis {")
+		for _, id := range ids {
+			fmt.Fprintf(out, "\t%q;\n", id)
+			for _, target := range edges[id] {
+				fmt.Fprintf(out, "\t%q -> %q;\n", id, target)
+			}
+		}
+		fmt.Fprintln(out, "}")
+		return nil
+	}
+
+	for _, id := range ids {
+		fmt.Fprintln(out, id)
+		for _, target := range edges[id] {
+			fmt.Fprintf(out, "\t-> %s\n", target)
+		}
+	}
+	return nil
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
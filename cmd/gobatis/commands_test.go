@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testMapper = `<mapper namespace="Image">
+  <sql id="Fields">${alias}.id, ${alias}.md5</sql>
+  <select id="Search">
+    SELECT <include refid="Fields"><property name="alias" value="gi"/></include>
+    FROM gc_image gi
+    <where><if test=".state">gi.state = #{state}</if></where>
+  </select>
+  <select id="Broken">SELECT <include refid="Missing"/> FROM gc_image</select>
+</mapper>`
+
+func testDir(t *testing.T) string {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "Image.xml"), []byte(testMapper), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "params.json"), []byte(`{"state": 1}`), 0o644))
+	return dir
+}
+
+func TestRender(t *testing.T) {
+	dir := testDir(t)
+	var out bytes.Buffer
+	err := render([]string{"Image.Search", "-mappers", dir, "-params", filepath.Join(dir, "params.json"), "-format", "compact"}, &out)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "SELECT gi.id, gi.md5 FROM gc_image gi WHERE gi.state = ?\n\n1\t1\n", out.String())
+	}
+
+	// 引用的片段不存在时返回错误而不是panic
+	assert.NotPanics(t, func() {
+		err = render([]string{"Image.Broken", "-mappers", dir}, &bytes.Buffer{})
+	})
+	assert.EqualError(t, err, "missing sql: Image.Missing")
+
+	assert.EqualError(t, render([]string{"Image.Nope", "-mappers", dir}, &bytes.Buffer{}), "render: missing sql Image.Nope")
+	assert.EqualError(t, render([]string{"Image.Search", "-mappers", dir, "-format", "ugly"}, &bytes.Buffer{}), "render: unknown format ugly")
+}
+
+func TestValidate(t *testing.T) {
+	dir := testDir(t)
+	var out bytes.Buffer
+	err := validate([]string{"-mappers", dir}, &out)
+	assert.EqualError(t, err, "validate: 1 problem(s) found")
+	assert.Contains(t, out.String(), "Image.Broken: missing sql: Image.Missing")
+}
+
+func TestList(t *testing.T) {
+	dir := testDir(t)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "Image.xml"), []byte(`<mapper namespace="Image">
+  <select id="Search">SELECT * FROM ${table} <where><if test=".state">state = #{state}</if></where></select>
+</mapper>`), 0o644))
+
+	var out bytes.Buffer
+	if assert.NoError(t, list([]string{"-mappers", dir}, &out)) {
+		assert.Equal(t, "Image.Search\tselect\n\tparams: state\n\tprops: table\n\ttested: state\n", out.String())
+	}
+}
+
+func TestGraph(t *testing.T) {
+	dir := testDir(t)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "Image.xml"), []byte(strings.Replace(testMapper, `<include refid="Missing"/>`, `*`, 1)), 0o644))
+
+	var out bytes.Buffer
+	if assert.NoError(t, graph([]string{"-mappers", dir, "-dot"}, &out)) {
+		assert.Contains(t, out.String(), "\t\"Image.Search\" -> \"Image.Fields\";\n")
+	}
+}
@@ -0,0 +1,74 @@
+// gobatis 渲染和检查mapper文件的命令行工具
+//
+//	gobatis render <id> -params params.json [-mappers dir] [-format pretty] [-trace]
+//	gobatis validate [-mappers dir]
+//	gobatis list [-mappers dir]
+//	gobatis graph [-mappers dir] [-dot]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string, out io.Writer) error
+}
+
+var commands = []command{
+	{name: "render", usage: "render <id> -params params.json  输出sql及参数列表", run: render},
+	{name: "validate", usage: "validate  检查mapper目录", run: validate},
+	{name: "list", usage: "list  输出所有sql id及其参数", run: list},
+	{name: "graph", usage: "graph  输出include依赖图", run: graph},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, c := range commands {
+		if c.name != os.Args[1] {
+			continue
+		}
+		if err := c.run(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "gobatis:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: gobatis <command> [arguments]")
+	for _, c := range commands {
+		fmt.Fprintln(os.Stderr, "  "+c.usage)
+	}
+}
+
+// parseFlags 解析参数，允许位置参数出现在选项之前，如 render <id> -params x.json
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func mappersFlag(fs *flag.FlagSet) *string {
+	return fs.String("mappers", ".", "mapper文件所在目录")
+}
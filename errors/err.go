@@ -2,6 +2,7 @@ package errors
 
 import (
 	"fmt"
+	"strings"
 )
 
 func MissingSQL(id string) error {
@@ -15,3 +16,94 @@ func MissingParameter(propName string) error {
 func TmplExecute(err error) error {
 	return fmt.Errorf("failed execute template: %w", err)
 }
+
+func CyclicInclude(path []string) error {
+	return fmt.Errorf("cyclic include: %s", strings.Join(path, " -> "))
+}
+
+func InvalidMapper(file string, err error) error {
+	return fmt.Errorf("invalid mapper %s: %w", file, err)
+}
+
+func UnsupportedElement(name string) error {
+	return fmt.Errorf("unsupported element: %s", name)
+}
+
+func MissingAttribute(elem, attr string) error {
+	return fmt.Errorf("missing attribute: %s.%s", elem, attr)
+}
+
+func DuplicateSQL(id string) error {
+	return fmt.Errorf("duplicate sql: %s", id)
+}
+
+func MissingVariant(dialect string) error {
+	return fmt.Errorf("missing sql variant for dialect: %q", dialect)
+}
+
+func InvalidParameter(name string, reason string) error {
+	return fmt.Errorf("invalid parameter %s: %s", name, reason)
+}
+
+func InvalidCursor(err error) error {
+	return fmt.Errorf("invalid cursor: %w", err)
+}
+
+func MissingField(typ string, field string) error {
+	return fmt.Errorf("missing field %s in %s", field, typ)
+}
+
+func UnsupportedStatement(stmt string, reason string) error {
+	return fmt.Errorf("unsupported statement %q: %s", stmt, reason)
+}
+
+// SortError 排序参数中含未允许的排序键或非法的方向
+type SortError struct {
+	Param  string
+	Key    string
+	Reason string
+}
+
+func (e *SortError) Error() string {
+	return fmt.Sprintf("invalid sort %s: %q %s", e.Param, e.Key, e.Reason)
+}
+
+func InvalidSort(param, key, reason string) error {
+	return &SortError{Param: param, Key: key, Reason: reason}
+}
+
+func UnsupportedReader(typ string) error {
+	return fmt.Errorf("unsupported reader %s: QueryContext is required", typ)
+}
+
+func InvalidResultMap(field string, err error) error {
+	return fmt.Errorf("invalid result map on %s: %w", field, err)
+}
+
+func UnknownDiscriminator(column string, value any) error {
+	return fmt.Errorf("unknown discriminator %s: %v", column, value)
+}
+
+func MissingTypeHandler(name string) error {
+	return fmt.Errorf("missing type handler: %s", name)
+}
+
+func TypeConvert(handler string, value any, err error) error {
+	return fmt.Errorf("type handler %s failed to convert %T: %w", handler, value, err)
+}
+
+func MissingScript(name string) error {
+	return fmt.Errorf("missing script: %s", name)
+}
+
+func PooledSession(op string) error {
+	return fmt.Errorf("%s requires a transaction or dedicated connection, got a connection pool", op)
+}
+
+func MissingUpsertKey(table string, key string) error {
+	return fmt.Errorf("upsert %s: missing key column %s", table, key)
+}
+
+func UnsupportedNamed(what string) error {
+	return fmt.Errorf("%s are not supported in named mode", what)
+}
@@ -0,0 +1,275 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/non1996/go-batis/errors"
+	"github.com/non1996/go-batis/sql"
+)
+
+// Options 代码生成选项
+type Options struct {
+	Package string   // 生成代码的包名
+	Imports []string // 结果类型所需的额外import
+}
+
+// Generate 根据mapper定义生成带类型的dao函数：
+// 每个select/insert/update/delete生成一个调用 implement.List/Get/Exec 的函数，参数类型为语句的 parameterType，
+// 未指定时生成字段均为 any 的参数结构体，值为nil的字段不作为参数，不含动态元素的语句在生成时预先渲染；
+// 带 selectKey 的 insert/update 生成以实体为参数的函数，实体类型同样取自 parameterType
+func Generate(mappers sql.Mappers, opts Options) ([]byte, error) {
+	collection, err := mappers.Collection()
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{
+		opts:       opts,
+		collection: collection,
+		statements: mappers.Statements(),
+	}
+	if err := g.generate(); err != nil {
+		return nil, err
+	}
+
+	src, err := format.Source(g.b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w\n%s", err, g.b.String())
+	}
+	return src, nil
+}
+
+type generator struct {
+	opts       Options
+	collection sql.Collection
+	statements []*sql.MappedStatement
+	b          bytes.Buffer
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.b, format, args...)
+}
+
+func (g *generator) generate() error {
+	g.printf("// Code generated by gobatis-gen. DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", g.opts.Package)
+	g.printf("import (\n")
+	g.printf("%q\n%q\n", "github.com/non1996/go-batis/implement", "github.com/non1996/go-batis/sql")
+	for _, imp := range g.opts.Imports {
+		g.printf("%q\n", imp)
+	}
+	g.printf(")\n\n")
+
+	g.printf("// ContextFactory 生成的函数创建 sql.Context 的方式，可替换以设置格式等选项\n")
+	g.printf("var ContextFactory = sql.NewContext\n\n")
+	g.printf("func prepare(id string, params sql.Parameters) *sql.Prepared {\n")
+	g.printf("return sql.PrepareID(ContextFactory().WithCollection(Collection).WithParams(params), id)\n")
+	g.printf("}\n\n")
+
+	if err := g.generateCollection(); err != nil {
+		return err
+	}
+	for _, s := range g.statements {
+		if s.Kind == sql.KindSQL {
+			continue
+		}
+		if err := g.generateStatement(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateCollection 生成所有sql定义，静态语句输出预渲染结果
+func (g *generator) generateCollection() error {
+	g.printf("// Collection 由mapper文件生成的sql定义\n")
+	g.printf("var Collection = sql.Collection{\n")
+	for _, s := range g.statements {
+		src, err := g.source(s.ID, s.SQL)
+		if s.SelectKey != nil {
+			// 带 selectKey 的语句以具名参数执行，不能预渲染为 ? 占位符
+			src, err = sql.GoSource(s.SQL)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.ID, err)
+		}
+		g.printf("%q: %s,\n", s.ID, src)
+		if s.SelectKey == nil {
+			continue
+		}
+		keyID := sql.SelectKeyID(s.ID)
+		if src, err = g.source(keyID, s.SelectKey.SQL); err != nil {
+			return fmt.Errorf("%s: %w", keyID, err)
+		}
+		g.printf("%q: %s,\n", keyID, src)
+	}
+	g.printf("}\n\n")
+	return nil
+}
+
+func (g *generator) source(id string, e sql.Elem) (string, error) {
+	set, err := g.collection.Parameters(id)
+	if err != nil {
+		return "", err
+	}
+	if !static(set) {
+		return sql.GoSource(e)
+	}
+
+	params := sql.MapParameters{}
+	for _, name := range set.Params() {
+		params[name] = nil
+	}
+	statement, err := sql.NewContext().
+		WithParams(params).
+		WithFormat(sql.FormatCompact).
+		Evaluate(e)
+	if err != nil {
+		return "", err
+	}
+	args := make([]string, 0, len(statement.ArgNames))
+	for _, name := range statement.ArgNames {
+		args = append(args, strconv.Quote(name))
+	}
+	return fmt.Sprintf("sql.NewStatement(%q, []string{%s})", statement.Stmt, strings.Join(args, ", ")), nil
+}
+
+// static 不含条件、属性及引用的语句可在生成时渲染
+func static(set *sql.ParameterSet) bool {
+	if len(set.Includes) != 0 || len(set.Groups) > 1 {
+		return false
+	}
+	for _, g := range set.Groups {
+		if len(g.Conditions) != 0 || len(g.Props) != 0 || len(g.Tested) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *generator) generateStatement(s *sql.MappedStatement) error {
+	if s.SelectKey != nil {
+		g.generateKeyStatement(s)
+		return nil
+	}
+
+	set, err := g.collection.Parameters(s.ID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", s.ID, err)
+	}
+
+	name := goName(s.ID)
+	names := uniqueNames(set)
+
+	var call, result string
+	switch s.Kind {
+	case sql.KindSelect:
+		if s.ResultType == "" {
+			return fmt.Errorf("%s: %w", s.ID, errors.MissingAttribute(string(s.Kind), "resultType"))
+		}
+		if s.ResultMode == "one" {
+			call, result = fmt.Sprintf("implement.Get[%s]", s.ResultType), "*"+s.ResultType
+		} else {
+			call, result = fmt.Sprintf("implement.List[%s]", s.ResultType), "[]"+s.ResultType
+		}
+	default:
+		call, result = "implement.Exec", "int64"
+	}
+
+	var paramsArg, paramsExpr string
+	switch {
+	case s.ParameterType != "":
+		paramsArg = ", params *" + s.ParameterType
+		paramsExpr = "sql.StructParameters(params)"
+	case len(names) == 0:
+		paramsExpr = "sql.MapParameters{}"
+	default:
+		paramsType := name + "Params"
+		g.printf("// %s %s 的参数，未指定 parameterType，字段均为 any，不做类型检查\n", paramsType, s.ID)
+		g.printf("type %s struct {\n", paramsType)
+		for _, n := range names {
+			g.printf("%s any\n", goName(n))
+		}
+		g.printf("}\n\n")
+
+		g.printf("// Parameters 转换为 sql.Parameters，值为nil的字段不作为参数\n")
+		g.printf("func (p *%s) Parameters() sql.MapParameters {\n", paramsType)
+		g.printf("params := sql.MapParameters{}\n")
+		for _, n := range names {
+			g.printf("if p.%s != nil {\nparams[%q] = p.%s\n}\n", goName(n), n, goName(n))
+		}
+		g.printf("return params\n}\n\n")
+
+		paramsArg = ", params *" + paramsType
+		paramsExpr = "params.Parameters()"
+	}
+
+	g.printf("// %s 执行 %s\n", name, s.ID)
+	g.printf("func %s(session implement.Session%s) (%s, error) {\n", name, paramsArg, result)
+	g.printf("return %s(session, prepare(%q, %s))\n", call, s.ID, paramsExpr)
+	g.printf("}\n\n")
+	return nil
+}
+
+// generateKeyStatement 带 selectKey 的语句以实体作为具名参数执行，并将生成的主键设置到实体，
+// selectKey 同样以实体作为参数，可引用实体字段；实体类型为语句的 parameterType，未指定时为 any
+func (g *generator) generateKeyStatement(s *sql.MappedStatement) {
+	name := goName(s.ID)
+	entityType := "any"
+	if s.ParameterType != "" {
+		entityType = "*" + s.ParameterType
+	}
+	g.printf("// %s 执行 %s，entity 作为具名参数，%s 由 selectKey 生成\n", name, s.ID, s.SelectKey.KeyProperty)
+	if s.ParameterType == "" {
+		g.printf("// 未指定 parameterType，entity 不做类型检查，selectKey 引用实体字段时须为结构体指针\n")
+	}
+	g.printf("func %s(session implement.Session, entity %s) error {\n", name, entityType)
+	g.printf("stmt, _, err := sql.PrepareID(ContextFactory().WithCollection(Collection).Named(), %q).Prepare()\n", s.ID)
+	g.printf("if err != nil {\nreturn err\n}\n")
+	g.printf("return implement.InsertWithKey(session, stmt, entity, implement.SelectKey{\n")
+	g.printf("Statement: prepare(%q, sql.StructParameters(entity)),\n", sql.SelectKeyID(s.ID))
+	g.printf("Property: %q,\n", s.SelectKey.KeyProperty)
+	g.printf("Before: %t,\n", s.SelectKey.Order == sql.KeyBefore)
+	g.printf("})\n}\n\n")
+}
+
+// uniqueNames 语句引用的全部参数名，包括条件中读取的参数
+func uniqueNames(set *sql.ParameterSet) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, group := range [][]string{set.Params(), set.Props(), set.Tested()} {
+		for _, n := range group {
+			if !seen[goName(n)] {
+				seen[goName(n)] = true
+				names = append(names, n)
+			}
+		}
+	}
+	return names
+}
+
+// goName 转换为导出的go标识符，如 Image.search_by_md5 -> ImageSearchByMd5
+func goName(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "X" + name
+	}
+	return name
+}
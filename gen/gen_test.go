@@ -0,0 +1,118 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/non1996/go-batis/sql"
+)
+
+func TestGenerate(t *testing.T) {
+	mappers, err := sql.LoadMappers("testdata")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	src, err := Generate(mappers, Options{Package: "dao"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	code := string(src)
+
+	// 静态语句预渲染
+	assert.Contains(t, code, `"Image.Get":    sql.NewStatement("SELECT * FROM gc_image WHERE id = ?", []string{"id"}),`)
+	// 动态语句输出构造代码
+	assert.Contains(t, code, `sql.Include("Image.Fields", false, sql.MapParameters{"alias": "gi"}),`)
+	// 参数结构体包含条件中读取的参数
+	assert.Contains(t, code, "type ImageSearchParams struct {\n\tState any\n\tMd5   any\n}")
+	assert.Contains(t, code, "func ImageSearch(session implement.Session, params *ImageSearchParams) ([]Image, error) {")
+	assert.Contains(t, code, "func ImageGet(session implement.Session, params *ImageGetParams) (*Image, error) {")
+	assert.Contains(t, code, "func ImageTouch(session implement.Session) (int64, error) {")
+	assert.NotContains(t, code, "ImageFields(")
+	// selectKey
+	assert.Contains(t, code, `"Image.Insert!selectKey": sql.NewStatement("SELECT nextval('gc_image_id_seq')", []string{}),`)
+	assert.Contains(t, code, "\"Image.Insert\": sql.Frag(`\n    INSERT INTO gc_image (id, md5) VALUES (#{id}, #{md5})")
+	assert.Contains(t, code, "func ImageInsert(session implement.Session, entity any) error {")
+	assert.Contains(t, code, "Before:    true,")
+	assert.Contains(t, code, `Statement: prepare("Image.Insert!selectKey", sql.StructParameters(entity)),`)
+}
+
+func TestGenerateKeyStatement(t *testing.T) {
+	m, err := sql.ParseMapper(strings.NewReader(`<mapper namespace="Image">
+  <insert id="Insert" parameterType="Image">
+    <selectKey keyProperty="id" order="BEFORE">SELECT next_id(#{tenantId})</selectKey>
+    INSERT INTO gc_image (id, tenant_id) VALUES (#{id}, #{tenantId})
+  </insert>
+</mapper>`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// 实体类型取自 parameterType，selectKey 可引用实体字段
+	src, err := Generate(sql.Mappers{m}, Options{Package: "dao"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	code := string(src)
+	assert.Contains(t, code, "func ImageInsert(session implement.Session, entity *Image) error {")
+	assert.Contains(t, code, `"Image.Insert!selectKey": sql.NewStatement("SELECT next_id(?)", []string{"tenantId"}),`)
+	assert.Contains(t, code, `Statement: prepare("Image.Insert!selectKey", sql.StructParameters(entity)),`)
+	assert.NotContains(t, code, "不做类型检查")
+}
+
+func TestGoName(t *testing.T) {
+	assert.Equal(t, "ImageSearchByMd5", goName("Image.search_by_md5"))
+	assert.Equal(t, "PlatformVersion", goName("platformVersion"))
+	assert.Equal(t, "X1st", goName("1st"))
+}
+
+func TestGenerateScript(t *testing.T) {
+	m, err := sql.ParseMapper(strings.NewReader(`<mapper namespace="Image">
+  <select id="ByID" resultType="Image" resultMode="one">
+    SELECT * FROM gc_image <where><script name="tenant"/> AND id = #{id}</where>
+  </select>
+</mapper>`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// 脚本求值时才能确定输出，不在生成时渲染
+	src, err := Generate(sql.Mappers{m}, Options{Package: "dao"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	code := string(src)
+	assert.Contains(t, code, `sql.ScriptRef("tenant")`)
+	assert.NotContains(t, code, `sql.NewStatement(`)
+}
+
+func TestGenerateParameters(t *testing.T) {
+	m, err := sql.ParseMapper(strings.NewReader(`<mapper namespace="Image">
+  <select id="Search" resultType="Image" parameterType="ImageQuery">
+    SELECT * FROM gc_image <where><if test=".state">state = #{state}</if></where>
+  </select>
+  <select id="ByMd5" resultType="Image">
+    SELECT * FROM gc_image <where><if test=".state">state = #{state}</if> AND md5 = #{md5}</where>
+  </select>
+</mapper>`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	src, err := Generate(sql.Mappers{m}, Options{Package: "dao"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	code := string(src)
+	// 指定 parameterType 时使用该类型
+	assert.Contains(t, code, "func ImageSearch(session implement.Session, params *ImageQuery) ([]Image, error) {\n\treturn implement.List[Image](session, prepare(\"Image.Search\", sql.StructParameters(params)))")
+	assert.NotContains(t, code, "ImageSearchParams")
+	// 值为nil的字段不作为参数
+	assert.Contains(t, code, "\tif p.State != nil {\n\t\tparams[\"state\"] = p.State\n\t}\n")
+
+	// 不同文件中的重复id
+	_, err = Generate(sql.Mappers{m, m}, Options{Package: "dao"})
+	assert.EqualError(t, err, "duplicate sql: Image.Search")
+}
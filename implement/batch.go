@@ -0,0 +1,95 @@
+package implement
+
+import (
+	"reflect"
+
+	"github.com/non1996/go-batis/errors"
+	"github.com/non1996/go-batis/sql"
+)
+
+// BatchOptions 批量插入的选项
+type BatchOptions[T any] struct {
+	Dialect  sql.Dialect
+	Rows     int            // 每条语句最多插入的行数，0 表示只受方言限制
+	IDSetter func(T, int64) // 设置自增id，为nil且T实现 Entity 时使用 Entity.SetID
+}
+
+// InsertBatch 按 statement（单行具名插入语句，同 Insert）分批生成多行插入语句，在当前session中依次执行。
+// MySQL、SQLite 下同一语句生成的自增id连续，由 LastInsertId 推算后设置到各实体，其他方言不设置id。
+// MySQL 须使用 innodb_autoinc_lock_mode 为0或1，为2时并发插入的id可能不连续，此时不应依赖设置的id；
+// 语句带 ON DUPLICATE KEY、ON CONFLICT 等尾部时，更新的行不生成新id，不设置id
+func InsertBatch[T any](
+	session Session,
+	statement string,
+	entities []T,
+	options BatchOptions[T],
+) (err error) {
+	if len(entities) == 0 {
+		return nil
+	}
+	batch, err := sql.ParseBatchInsert(statement)
+	if err != nil {
+		return err
+	}
+
+	size := batch.ChunkSize(options.Dialect, options.Rows)
+	for start := 0; start < len(entities); start += size {
+		chunk := entities[start:min(start+size, len(entities))]
+		args := make([]any, 0, len(chunk)*len(batch.Names))
+		for _, entity := range chunk {
+			row := reflect.ValueOf(entity)
+			for _, name := range batch.Names {
+				value, ok := fieldValue(row, name)
+				if !ok {
+					return errors.MissingParameter(name)
+				}
+				args = append(args, value)
+			}
+		}
+
+		res, err := getW(session).ExecContext(session, batch.Render(len(chunk), options.Dialect), args...)
+		if err != nil {
+			return err
+		}
+		if batch.HasTail() {
+			continue
+		}
+		if err = setBatchIDs(res, chunk, options); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setBatchIDs MySQL 的 LastInsertId 为第一行的id，SQLite 的为最后一行的id
+func setBatchIDs[T any](res interface{ LastInsertId() (int64, error) }, chunk []T, options BatchOptions[T]) error {
+	setter := options.IDSetter
+	if setter == nil {
+		if _, ok := any(chunk[0]).(Entity); !ok {
+			return nil
+		}
+		setter = func(entity T, id int64) { any(entity).(Entity).SetID(id) }
+	}
+
+	var first int64
+	switch options.Dialect {
+	case sql.MySQL:
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		first = id
+	case sql.SQLite:
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		first = id - int64(len(chunk)) + 1
+	default:
+		return nil
+	}
+	for i, entity := range chunk {
+		setter(entity, first+int64(i))
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package implement
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/non1996/go-batis/sql"
+)
+
+type testRow struct {
+	ID  int64  `db:"id"`
+	Md5 string `db:"md5"`
+}
+
+func (r *testRow) SetID(id int64) {
+	r.ID = id
+}
+
+func TestInsertBatch(t *testing.T) {
+	insert := `INSERT INTO gc_image (md5) VALUES (:md5)`
+	rows := func() []*testRow {
+		return []*testRow{{Md5: "a"}, {Md5: "b"}, {Md5: "c"}, {Md5: "d"}, {Md5: "e"}}
+	}
+
+	// 分批执行，MySQL 的 LastInsertId 为每批第一行的id
+	db := &testDB{results: []testResult{{lastID: 10}, {lastID: 20}, {lastID: 30}}}
+	entities := rows()
+	err := InsertBatch(Set(context.Background(), db), insert, entities, BatchOptions[*testRow]{Dialect: sql.MySQL, Rows: 2})
+	if assert.NoError(t, err) && assert.Len(t, db.execs, 3) {
+		assert.Equal(t, `INSERT INTO gc_image (md5) VALUES (?), (?)`, db.execs[0].query)
+		assert.Equal(t, []any{"a", "b"}, db.execs[0].args)
+		assert.Equal(t, `INSERT INTO gc_image (md5) VALUES (?)`, db.execs[2].query)
+		assert.Equal(t, []int64{10, 11, 20, 21, 30}, ids(entities))
+	}
+
+	// SQLite 的 LastInsertId 为最后一行的id
+	db = &testDB{results: []testResult{{lastID: 13}, {lastID: 15}}}
+	entities = rows()
+	err = InsertBatch(Set(context.Background(), db), insert, entities, BatchOptions[*testRow]{Dialect: sql.SQLite, Rows: 3})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int64{11, 12, 13, 14, 15}, ids(entities))
+	}
+
+	// 带冲突处理时更新的行不生成新id，不设置id
+	db = &testDB{results: []testResult{{lastID: 10}}}
+	entities = rows()
+	err = InsertBatch(Set(context.Background(), db), insert+` ON DUPLICATE KEY UPDATE md5 = VALUES(md5)`, entities, BatchOptions[*testRow]{Dialect: sql.MySQL})
+	if assert.NoError(t, err) && assert.Len(t, db.execs, 1) {
+		assert.Equal(t, []int64{0, 0, 0, 0, 0}, ids(entities))
+	}
+
+	// 其他方言不设置id，按方言输出占位符
+	db = &testDB{}
+	entities = rows()
+	err = InsertBatch(Set(context.Background(), db), insert, entities[:2], BatchOptions[*testRow]{Dialect: sql.PostgreSQL})
+	if assert.NoError(t, err) && assert.Len(t, db.execs, 1) {
+		assert.Equal(t, `INSERT INTO gc_image (md5) VALUES ($1), ($2)`, db.execs[0].query)
+		assert.Equal(t, []int64{0, 0}, ids(entities[:2]))
+	}
+
+	err = InsertBatch(Set(context.Background(), &testDB{}), `INSERT INTO gc_image (md5, size) VALUES (:md5, :size)`, rows(), BatchOptions[*testRow]{})
+	assert.EqualError(t, err, "missing parameter: size")
+}
+
+func ids(rows []*testRow) []int64 {
+	res := make([]int64, 0, len(rows))
+	for _, r := range rows {
+		res = append(res, r.ID)
+	}
+	return res
+}
@@ -0,0 +1,140 @@
+package implement
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+type testExec struct {
+	query string
+	args  []any
+}
+
+type testResult struct {
+	lastID   int64
+	affected int64
+}
+
+func (r testResult) LastInsertId() (int64, error) { return r.lastID, nil }
+func (r testResult) RowsAffected() (int64, error) { return r.affected, nil }
+
+// testDB 记录执行的语句，按顺序返回预设结果的 ReaderWriter，相当于事务或独占连接
+type testDB struct {
+	execs   []testExec
+	results []testResult // Exec 依次返回的结果，用完后返回零值
+	values  []any        // Get 依次扫描的单值
+	rows    testRows     // QueryContext 返回的结果
+}
+
+func (db *testDB) Exec(query string, args ...any) (sql.Result, error) {
+	return db.ExecContext(context.Background(), query, args...)
+}
+
+func (db *testDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	db.execs = append(db.execs, testExec{query: query, args: args})
+	if len(db.results) == 0 {
+		return testResult{}, nil
+	}
+	r := db.results[0]
+	db.results = db.results[1:]
+	return r, nil
+}
+
+func (db *testDB) NamedExec(query string, arg any) (sql.Result, error) {
+	return db.ExecContext(context.Background(), query, arg)
+}
+
+func (db *testDB) NamedExecContext(ctx context.Context, query string, arg any) (sql.Result, error) {
+	return db.ExecContext(ctx, query, arg)
+}
+
+func (db *testDB) Get(dest any, query string, args ...any) error {
+	return db.GetContext(context.Background(), dest, query, args...)
+}
+
+func (db *testDB) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	db.execs = append(db.execs, testExec{query: query, args: args})
+	if len(db.values) == 0 {
+		return sql.ErrNoRows
+	}
+	v := db.values[0]
+	db.values = db.values[1:]
+	target := reflect.ValueOf(dest).Elem()
+	if v == nil {
+		target.SetZero()
+		return nil
+	}
+	target.Set(reflect.ValueOf(v).Convert(target.Type()))
+	return nil
+}
+
+func (db *testDB) Select(dest any, query string, args ...any) error {
+	return db.SelectContext(context.Background(), dest, query, args...)
+}
+
+func (db *testDB) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	return fmt.Errorf("testDB: select is not supported")
+}
+
+func (db *testDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	db.execs = append(db.execs, testExec{query: query, args: args})
+	return sql.OpenDB(testConnector{rows: db.rows}).QueryContext(ctx, query)
+}
+
+// testRows 预设的查询结果，通过内存驱动返回 *sql.Rows
+type testRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+type testConnector struct {
+	rows testRows
+}
+
+func (c testConnector) Connect(context.Context) (driver.Conn, error) { return testConn(c), nil }
+func (c testConnector) Driver() driver.Driver                        { return c }
+func (c testConnector) Open(string) (driver.Conn, error)             { return testConn(c), nil }
+
+type testConn testConnector
+
+func (c testConn) Prepare(string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("testConn: prepare is not supported")
+}
+func (c testConn) Close() error { return nil }
+func (c testConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("testConn: begin is not supported")
+}
+
+func (c testConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &testDriverRows{testRows: c.rows}, nil
+}
+
+type testDriverRows struct {
+	testRows
+	next int
+}
+
+func (r *testDriverRows) Columns() []string { return r.columns }
+func (r *testDriverRows) Close() error      { return nil }
+
+func (r *testDriverRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.next])
+	r.next++
+	return nil
+}
+
+// testPool 连接池，每次调用可能使用不同的连接
+type testPool struct {
+	testDB
+}
+
+func (p *testPool) Conn(ctx context.Context) (*sql.Conn, error) {
+	return nil, fmt.Errorf("testPool: conn is not supported")
+}
@@ -11,6 +11,8 @@ type Entity interface {
 	SetID(int64)
 }
 
+// Insert 执行具名插入语句并以 LastInsertId 设置实体id。
+// 驱动不支持 LastInsertId 时（如 lib/pq、SQL Server）id 为0，这些方言应使用 InsertWithKey
 func Insert(
 	session Session,
 	statement string,
@@ -25,6 +27,7 @@ func Insert(
 	return nil
 }
 
+// InsertWithIDSetter 同 Insert，通过 idSetter 设置实体id
 func InsertWithIDSetter[T any](
 	session Session,
 	statement string,
@@ -44,7 +47,7 @@ func Exec(
 	session Session,
 	statement Statement,
 ) (affected int64, err error) {
-	stmt, args, err := statement.Prepare()
+	stmt, args, err := prepare(session, statement)
 	if err != nil {
 		return 0, err
 	}
@@ -65,7 +68,7 @@ func NamedExec(
 	session Session,
 	statement Statement,
 ) (affected int64, err error) {
-	stmt, arg, err := statement.Prepare()
+	stmt, arg, err := prepare(session, statement)
 	if err != nil {
 		return 0, err
 	}
@@ -86,7 +89,7 @@ func List[T any](
 	session Session,
 	statement Statement,
 ) (list []T, err error) {
-	stmt, args, err := statement.Prepare()
+	stmt, args, err := prepare(session, statement)
 	if err != nil {
 		return nil, err
 	}
@@ -106,7 +109,7 @@ func Get[T any](
 	session Session,
 	statement Statement,
 ) (res *T, err error) {
-	stmt, args, err := statement.Prepare()
+	stmt, args, err := prepare(session, statement)
 	if err != nil {
 		return nil, err
 	}
@@ -127,7 +130,7 @@ func GetNoPtr[T any](
 	session Session,
 	statement Statement,
 ) (res T, err error) {
-	stmt, args, err := statement.Prepare()
+	stmt, args, err := prepare(session, statement)
 	if err != nil {
 		return function.Zero[T](), err
 	}
@@ -157,6 +160,14 @@ func Count(
 	return GetNoPtr[int64](session, statement)
 }
 
+// prepare 生成语句，语句支持时传入session作为请求的 context.Context
+func prepare(session Session, statement Statement) (string, []any, error) {
+	if s, ok := statement.(ContextStatement); ok {
+		return s.PrepareContext(session)
+	}
+	return statement.Prepare()
+}
+
 func NoRow(err error) bool {
 	return errors2.Is(err, sql.ErrNoRows)
 }
@@ -19,6 +19,11 @@ type Reader interface {
 	SelectContext(ctx context.Context, dest any, query string, args ...any) error
 }
 
+// Querier 可返回原始结果集的读client，sqlx.DB/sqlx.Tx 均实现，ListMapped 需要
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
 type ReaderWriter interface {
 	Reader
 	Writer
@@ -34,6 +39,12 @@ type Statement interface {
 	Prepare() (string, []any, error)
 }
 
+// ContextStatement 需要请求 context.Context 才能生成的语句，执行时以session调用 PrepareContext
+type ContextStatement interface {
+	Statement
+	PrepareContext(ctx context.Context) (string, []any, error)
+}
+
 type NamedStatement interface {
 	Prepare() (string, any, error)
 }
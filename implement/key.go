@@ -0,0 +1,78 @@
+package implement
+
+import (
+	"context"
+	stdsql "database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/non1996/go-batis/errors"
+	"github.com/non1996/go-batis/sql"
+)
+
+// SelectKey 插入语句前后执行的主键生成语句，如 SELECT nextval('seq')、SELECT LAST_INSERT_ID()
+type SelectKey struct {
+	Statement Statement // 返回单行单列的查询
+	Property  string    // 结果赋值的实体属性，按 db 标签或字段名匹配
+	Before    bool      // 在插入前执行，插入语句可引用生成的主键
+}
+
+// pool 连接池，如 sql.DB/sqlx.DB，每次调用可能使用不同的连接
+type pool interface {
+	Conn(ctx context.Context) (*stdsql.Conn, error)
+}
+
+// InsertWithKey 同 Insert，主键由 key 生成而不是 LastInsertId，entity 须为结构体指针或 map[string]any。
+// key 在当前session中执行。插入后执行的 key（如 LAST_INSERT_ID()）依赖连接状态，
+// session 须为事务（如 sqlx.Tx）或独占连接，为连接池时返回错误
+func InsertWithKey(
+	session Session,
+	statement string,
+	entity any,
+	key SelectKey,
+) (err error) {
+	if !key.Before {
+		if _, ok := getW(session).(pool); ok {
+			return errors.PooledSession("selectKey after insert")
+		}
+	}
+	if key.Before {
+		if err = selectKey(session, entity, key); err != nil {
+			return err
+		}
+	}
+	if _, err = getW(session).NamedExecContext(session, statement, entity); err != nil {
+		return err
+	}
+	if !key.Before {
+		return selectKey(session, entity, key)
+	}
+	return nil
+}
+
+// selectKey 执行主键生成语句，结果直接扫描到实体属性中
+func selectKey(session Session, entity any, key SelectKey) error {
+	stmt, args, err := prepare(session, key.Statement)
+	if err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(entity)
+	if v.Kind() == reflect.Map {
+		var value any
+		if err = getR(session).GetContext(session, &value, stmt, args...); err != nil {
+			return err
+		}
+		v.SetMapIndex(reflect.ValueOf(key.Property), reflect.ValueOf(value))
+		return nil
+	}
+
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return errors.MissingField(fmt.Sprintf("%T", entity), key.Property)
+	}
+	f, ok := sql.StructField(v.Elem(), key.Property)
+	if !ok || !f.CanAddr() {
+		return errors.MissingField(fmt.Sprintf("%T", entity), key.Property)
+	}
+	return getR(session).GetContext(session, f.Addr().Interface(), stmt, args...)
+}
@@ -0,0 +1,63 @@
+package implement
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/non1996/go-batis/sql"
+)
+
+type testKeyed struct {
+	ID  int64  `db:"id"`
+	Md5 string `db:"md5"`
+}
+
+type testBase struct {
+	ID int64 `db:"id"`
+}
+
+type testEmbedded struct {
+	testBase
+	Md5 string `db:"md5"`
+}
+
+func TestInsertWithKey(t *testing.T) {
+	insert := `INSERT INTO gc_image (id, md5) VALUES (:id, :md5)`
+	nextval := sql.Prepare(sql.NewContext(), sql.Frag(`SELECT nextval('gc_image_id_seq')`))
+
+	// 插入前生成主键
+	db := &testDB{values: []any{int64(42)}}
+	entity := &testKeyed{Md5: "abc"}
+	err := InsertWithKey(Set(context.Background(), db), insert, entity, SelectKey{Statement: nextval, Property: "id", Before: true})
+	if assert.NoError(t, err) && assert.Len(t, db.execs, 2) {
+		assert.Equal(t, int64(42), entity.ID)
+		assert.Equal(t, `SELECT nextval('gc_image_id_seq')`, db.execs[0].query)
+		assert.Equal(t, insert, db.execs[1].query)
+	}
+
+	// 与 StructParameters 相同的字段规则：嵌入结构体、不区分大小写
+	db = &testDB{values: []any{int64(43)}}
+	embedded := &testEmbedded{Md5: "abc"}
+	err = InsertWithKey(Set(context.Background(), db), insert, embedded, SelectKey{Statement: nextval, Property: "ID", Before: true})
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(43), embedded.ID)
+	}
+
+	// 插入后读取主键
+	lastID := sql.Prepare(sql.NewContext(), sql.Frag(`SELECT LAST_INSERT_ID()`))
+	db = &testDB{values: []any{int64(7)}}
+	m := map[string]any{"md5": "abc"}
+	err = InsertWithKey(Set(context.Background(), db), insert, m, SelectKey{Statement: lastID, Property: "id"})
+	if assert.NoError(t, err) && assert.Len(t, db.execs, 2) {
+		assert.Equal(t, int64(7), m["id"])
+		assert.Equal(t, insert, db.execs[0].query)
+	}
+
+	// 连接池中插入后读取主键可能使用其他连接
+	pool := &testPool{testDB{values: []any{int64(7)}}}
+	err = InsertWithKey(Set(context.Background(), pool), insert, &testKeyed{}, SelectKey{Statement: lastID, Property: "id"})
+	assert.EqualError(t, err, "selectKey after insert requires a transaction or dedicated connection, got a connection pool")
+	assert.Empty(t, pool.execs)
+}
@@ -0,0 +1,36 @@
+package implement
+
+import (
+	"github.com/non1996/go-batis/sql"
+)
+
+// PageResult 分页查询结果
+type PageResult[T any] struct {
+	Items []T   `json:"items"`
+	Total int64 `json:"total"`
+	Page  int64 `json:"page"`
+	Size  int64 `json:"size"`
+}
+
+// Page 执行含 sql.Page/sql.Paginate 的列表查询及由其派生的计数查询
+func Page[T any](
+	session Session,
+	statement *sql.Prepared,
+) (res *PageResult[T], err error) {
+	stmt, args, page, err := statement.PreparePage(session)
+	if err != nil {
+		return nil, err
+	}
+
+	res = &PageResult[T]{Page: page.Page, Size: page.Size}
+	err = getR(session).SelectContext(session, &res.Items, stmt, args...)
+	if err != nil && !NoRow(err) {
+		return nil, err
+	}
+
+	res.Total, err = Count(session, statement.Count())
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
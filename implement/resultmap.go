@@ -0,0 +1,356 @@
+package implement
+
+import (
+	stdsql "database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/non1996/go-batis/errors"
+	"github.com/non1996/go-batis/sql"
+)
+
+// ResultMap 结果映射，将联表查询的多行结果按主键合并为带嵌套关联的结构体
+//
+//	SELECT i.id, i.md5, p.id AS 'profile.id', b.path AS 'bundles.path' ...
+//
+//	&ResultMap{
+//		ID:           "id",
+//		Associations: []Nested{{Field: "Profile", Prefix: "profile.", Map: &ResultMap{ID: "id"}}},
+//		Collections:  []Nested{{Field: "Bundles", Prefix: "bundles.", Map: &ResultMap{ID: "path"}}},
+//	}
+//
+// Handlers 中类型处理器的 FromDB 只在 ListMapped 中生效，List/Get 等由 sqlx 直接扫描，不经过类型处理器，
+// 需要转换时字段类型实现 sql.Scanner 或改用 ListMapped
+type ResultMap struct {
+	ID            string            // 分组的主键列（不含前缀），为空时每行为一个对象
+	Columns       map[string]string // 列名（不含前缀） -> 字段名，未列出的列按 db 标签或字段名匹配
+	Associations  []Nested          // 一对一关联
+	Collections   []Nested          // 一对多关联
+	Discriminator *Discriminator    // 按列值选择具体类型
+	Handlers      *sql.TypeHandlers // 列值的类型处理器，按列名（含前缀）或字段类型查找，只在最外层设置，为nil时使用 sql.DefaultTypeHandlers
+}
+
+// Discriminator 鉴别器，按列值选择行映射的具体类型及结果映射，与mybatis的 discriminator 一致。
+// 目标类型为接口（如 ListMapped[Creation]）时，列值必须有对应的 Case 且 Case 须指定具体类型
+//
+//	Discriminator: &Discriminator{Column: "type", Cases: map[string]Case{
+//		"video": CaseOf[*Video](nil),
+//		"image": CaseOf[*Image](&ResultMap{ID: "id", Columns: map[string]string{"w": "Width"}}),
+//	}}
+type Discriminator struct {
+	Column string          // 鉴别列（不含前缀）
+	Cases  map[string]Case // 列值 -> 具体类型
+}
+
+// Case 鉴别器的一个分支
+type Case struct {
+	Type reflect.Type // 具体类型，须可赋值给目标类型
+	Map  *ResultMap   // 为nil时使用外层结果映射
+}
+
+// CaseOf 以T为具体类型的分支
+func CaseOf[T any](resultMap *ResultMap) Case {
+	return Case{Type: reflect.TypeOf((*T)(nil)).Elem(), Map: resultMap}
+}
+
+// Nested 嵌套的关联对象
+type Nested struct {
+	Field  string // 父对象中的字段，按 db 标签或字段名匹配
+	Prefix string // 关联对象的列名前缀，如 profile.
+	Map    *ResultMap
+}
+
+// ListMapped 执行查询并按 ResultMap 将多行结果合并为嵌套结构体，T 为结构体、结构体指针，
+// 或使用 Discriminator 时各具体类型实现的接口
+func ListMapped[T any](
+	session Session,
+	statement Statement,
+	resultMap *ResultMap,
+) (list []T, err error) {
+	stmt, args, err := prepare(session, statement)
+	if err != nil {
+		return nil, err
+	}
+
+	r := getR(session)
+	q, ok := r.(Querier)
+	if !ok {
+		return nil, errors.UnsupportedReader(fmt.Sprintf("%T", r))
+	}
+	rows, err := q.QueryContext(session, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records, err := scanRecords(rows)
+	if err != nil {
+		return nil, err
+	}
+	m := &resultMapper{handlers: resultMap.Handlers}
+	if m.handlers == nil {
+		m.handlers = sql.DefaultTypeHandlers
+	}
+	values, err := m.records(records, resultMap, "", reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+	list = make([]T, 0, len(values))
+	for _, v := range values {
+		list = append(list, v.Interface().(T))
+	}
+	return list, nil
+}
+
+// record 一行结果，列名 -> 值
+type record map[string]any
+
+func scanRecords(rows *stdsql.Rows) ([]record, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var records []record
+	for rows.Next() {
+		values := make([]any, len(columns))
+		dest := make([]any, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		r := make(record, len(columns))
+		for i, c := range columns {
+			r[c] = values[i]
+		}
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
+
+// resultMapper 按 ResultMap 将行映射为对象
+type resultMapper struct {
+	handlers *sql.TypeHandlers
+}
+
+// records 按主键分组，每组生成一个typ类型的对象，保持首次出现的顺序；主键为NULL的行（如左连接未匹配）不生成对象
+func (m *resultMapper) records(records []record, rm *ResultMap, prefix string, typ reflect.Type) ([]reflect.Value, error) {
+	var keys []string
+	groups := map[string][]record{}
+	for i, r := range records {
+		key := strconv.Itoa(i)
+		if rm.ID != "" {
+			id := r[prefix+rm.ID]
+			if id == nil {
+				continue
+			}
+			key = groupKey(id)
+		}
+		if _, exist := groups[key]; !exist {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], r)
+	}
+
+	values := make([]reflect.Value, 0, len(keys))
+	for _, key := range keys {
+		v, err := m.group(groups[key], rm, prefix, typ)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func groupKey(id any) string {
+	if b, ok := id.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprint(id)
+}
+
+// group 由同一主键的行生成对象，列取自第一行，关联对象取自全部行
+func (m *resultMapper) group(group []record, rm *ResultMap, prefix string, typ reflect.Type) (reflect.Value, error) {
+	if rm.Discriminator != nil {
+		return m.discriminate(group, rm, prefix, typ)
+	}
+
+	if typ.Kind() == reflect.Interface {
+		return reflect.Value{}, errors.InvalidResultMap(typ.String(), fmt.Errorf("interface requires a discriminator case with a concrete type"))
+	}
+
+	ptr := reflect.New(indirect(typ))
+	obj := ptr.Elem()
+
+	for column, value := range group[0] {
+		name, ok := strings.CutPrefix(column, prefix)
+		if !ok || strings.Contains(name, ".") {
+			continue
+		}
+		field := name
+		if mapped, exist := rm.Columns[name]; exist {
+			field = mapped
+		}
+		f, ok := sql.StructField(obj, field)
+		if !ok {
+			continue
+		}
+		// 类型处理器按列名查找，与 Columns 是否重命名无关
+		if err := m.assign(prefix+name, f, value); err != nil {
+			return reflect.Value{}, errors.InvalidResultMap(prefix+name, err)
+		}
+	}
+
+	for _, a := range rm.Associations {
+		f, ok := sql.StructField(obj, a.Field)
+		if !ok {
+			return reflect.Value{}, errors.MissingField(obj.Type().String(), a.Field)
+		}
+		children, err := m.records(group, a.Map, prefix+a.Prefix, f.Type())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if len(children) != 0 {
+			f.Set(children[0])
+		}
+	}
+	for _, c := range rm.Collections {
+		f, ok := sql.StructField(obj, c.Field)
+		if !ok || f.Kind() != reflect.Slice {
+			return reflect.Value{}, errors.MissingField(obj.Type().String(), c.Field)
+		}
+		children, err := m.records(group, c.Map, prefix+c.Prefix, f.Type().Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		slice := reflect.MakeSlice(f.Type(), 0, len(children))
+		f.Set(reflect.Append(slice, children...))
+	}
+
+	if typ.Kind() == reflect.Pointer {
+		return ptr, nil
+	}
+	return obj, nil
+}
+
+// discriminate 按鉴别列的值选择具体类型映射，结果转换为目标类型
+func (m *resultMapper) discriminate(group []record, rm *ResultMap, prefix string, typ reflect.Type) (reflect.Value, error) {
+	d := rm.Discriminator
+	value := group[0][prefix+d.Column]
+	c, exist := d.Cases[groupKey(value)]
+	if !exist && typ.Kind() == reflect.Interface {
+		return reflect.Value{}, errors.UnknownDiscriminator(prefix+d.Column, groupKey(value))
+	}
+
+	concrete, caseMap := typ, *rm
+	caseMap.Discriminator = nil
+	if c.Type != nil {
+		if !c.Type.AssignableTo(typ) {
+			return reflect.Value{}, errors.InvalidResultMap(prefix+d.Column, fmt.Errorf("%s is not assignable to %s", c.Type, typ))
+		}
+		concrete = c.Type
+	}
+	if c.Map != nil {
+		caseMap = *c.Map
+	}
+
+	v, err := m.group(group, &caseMap, prefix, concrete)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if typ.Kind() == reflect.Interface {
+		res := reflect.New(typ).Elem()
+		res.Set(v)
+		return res, nil
+	}
+	return v, nil
+}
+
+func indirect(typ reflect.Type) reflect.Type {
+	if typ.Kind() == reflect.Pointer {
+		return typ.Elem()
+	}
+	return typ
+}
+
+// assign 将驱动返回的值赋给字段，优先使用类型处理器
+func (m *resultMapper) assign(column string, f reflect.Value, value any) error {
+	th, exist, err := m.handlers.Lookup("", column, f.Type())
+	if err != nil {
+		return err
+	}
+	if exist {
+		return th.FromDB(value, f.Addr().Interface())
+	}
+	return assign(f, value)
+}
+
+// assign 将驱动返回的值赋给字段，支持 database/sql.Scanner、指针字段、可转换类型及文本形式的数字
+func assign(f reflect.Value, value any) error {
+	if scanner, ok := f.Addr().Interface().(stdsql.Scanner); ok {
+		return scanner.Scan(value)
+	}
+	if value == nil {
+		f.Set(reflect.Zero(f.Type()))
+		return nil
+	}
+	if f.Kind() == reflect.Pointer {
+		elem := reflect.New(f.Type().Elem())
+		if err := assign(elem.Elem(), value); err != nil {
+			return err
+		}
+		f.Set(elem)
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	if b, ok := value.([]byte); ok && f.Kind() != reflect.Slice {
+		v = reflect.ValueOf(string(b))
+	}
+	if v.Kind() == reflect.String {
+		s := v.String()
+		switch f.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return err
+			}
+			f.SetInt(n)
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				return err
+			}
+			f.SetUint(n)
+			return nil
+		case reflect.Float32, reflect.Float64:
+			n, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return err
+			}
+			f.SetFloat(n)
+			return nil
+		case reflect.Bool:
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return err
+			}
+			f.SetBool(b)
+			return nil
+		}
+	}
+	if f.Kind() == reflect.String && v.Kind() != reflect.String {
+		f.SetString(fmt.Sprint(value))
+		return nil
+	}
+	if !v.Type().ConvertibleTo(f.Type()) {
+		return fmt.Errorf("cannot assign %T to %s", value, f.Type())
+	}
+	f.Set(v.Convert(f.Type()))
+	return nil
+}
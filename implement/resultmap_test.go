@@ -0,0 +1,162 @@
+package implement
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/non1996/go-batis/sql"
+)
+
+type testProfile struct {
+	ID   int64  `db:"id"`
+	Nick string `db:"nick"`
+}
+
+type testBundle struct {
+	Path string `db:"path"`
+}
+
+type testImage struct {
+	ID      int64 `db:"id"`
+	Md5     string
+	Width   *int
+	Profile *testProfile `db:"profile"`
+	Bundles []testBundle `db:"bundles"`
+}
+
+func TestMapRecords(t *testing.T) {
+	records := []record{
+		{"id": int64(1), "md5": []byte("a"), "w": []byte("100"), "profile.id": int64(7), "profile.name": "cat", "bundles.path": "/1"},
+		{"id": int64(1), "md5": []byte("a"), "w": []byte("100"), "profile.id": int64(7), "profile.name": "cat", "bundles.path": "/2"},
+		{"id": int64(2), "md5": []byte("b"), "w": nil, "profile.id": nil, "profile.name": nil, "bundles.path": nil},
+	}
+	rm := &ResultMap{
+		ID:           "id",
+		Columns:      map[string]string{"w": "Width"},
+		Associations: []Nested{{Field: "profile", Prefix: "profile.", Map: &ResultMap{ID: "id", Columns: map[string]string{"name": "nick"}}}},
+		Collections:  []Nested{{Field: "bundles", Prefix: "bundles.", Map: &ResultMap{ID: "path"}}},
+	}
+
+	values, err := (&resultMapper{handlers: sql.NewTypeHandlers()}).records(records, rm, "", reflect.TypeOf(&testImage{}))
+	if !assert.NoError(t, err) || !assert.Len(t, values, 2) {
+		return
+	}
+	width := 100
+	assert.Equal(t, &testImage{
+		ID:      1,
+		Md5:     "a",
+		Width:   &width,
+		Profile: &testProfile{ID: 7, Nick: "cat"},
+		Bundles: []testBundle{{Path: "/1"}, {Path: "/2"}},
+	}, values[0].Interface())
+	assert.Equal(t, &testImage{ID: 2, Md5: "b", Bundles: []testBundle{}}, values[1].Interface())
+
+	// 类型处理器
+	handlers := sql.NewTypeHandlers()
+	handlers.RegisterParam("profile.name", sql.CSV("|"))
+	var tagged struct {
+		Name []string `db:"name"`
+	}
+	m := &resultMapper{handlers: handlers}
+	values, err = m.records([]record{{"profile.name": "a|b"}}, &ResultMap{}, "profile.", reflect.TypeOf(tagged))
+	if assert.NoError(t, err) && assert.Len(t, values, 1) {
+		assert.Equal(t, []string{"a", "b"}, values[0].Field(0).Interface())
+	}
+
+	// 按列名注册的类型处理器在列被 Columns 重命名后仍然生效
+	handlers = sql.NewTypeHandlers()
+	handlers.RegisterParam("tag_list", sql.CSV(","))
+	var renamed struct {
+		Tags []string
+	}
+	m = &resultMapper{handlers: handlers}
+	values, err = m.records([]record{{"tag_list": []byte("a,b")}}, &ResultMap{Columns: map[string]string{"tag_list": "Tags"}}, "", reflect.TypeOf(renamed))
+	if assert.NoError(t, err) && assert.Len(t, values, 1) {
+		assert.Equal(t, []string{"a", "b"}, values[0].Field(0).Interface())
+	}
+
+	// 非指针类型
+	values, err = (&resultMapper{handlers: sql.NewTypeHandlers()}).records(records[:1], &ResultMap{}, "profile.", reflect.TypeOf(testProfile{}))
+	if assert.NoError(t, err) && assert.Len(t, values, 1) {
+		assert.Equal(t, testProfile{ID: 7}, values[0].Interface())
+	}
+}
+
+type testCreation interface {
+	kind() string
+}
+
+type testVideo struct {
+	ID       int64 `db:"id"`
+	Duration int64 `db:"duration"`
+}
+
+func (v *testVideo) kind() string { return "video" }
+
+type testPicture struct {
+	ID    int64 `db:"id"`
+	Width int64
+}
+
+func (p testPicture) kind() string { return "picture" }
+
+func TestDiscriminator(t *testing.T) {
+	records := []record{
+		{"id": int64(1), "type": []byte("video"), "duration": int64(30), "w": nil},
+		{"id": int64(2), "type": []byte("picture"), "duration": nil, "w": int64(640)},
+	}
+	rm := &ResultMap{
+		ID: "id",
+		Discriminator: &Discriminator{Column: "type", Cases: map[string]Case{
+			"video":   CaseOf[*testVideo](nil),
+			"picture": CaseOf[testPicture](&ResultMap{ID: "id", Columns: map[string]string{"w": "Width"}}),
+		}},
+	}
+
+	values, err := (&resultMapper{handlers: sql.NewTypeHandlers()}).records(records, rm, "", reflect.TypeOf((*testCreation)(nil)).Elem())
+	if assert.NoError(t, err) && assert.Len(t, values, 2) {
+		assert.Equal(t, &testVideo{ID: 1, Duration: 30}, values[0].Interface())
+		assert.Equal(t, testPicture{ID: 2, Width: 640}, values[1].Interface())
+	}
+
+	_, err = (&resultMapper{handlers: sql.NewTypeHandlers()}).records([]record{{"id": int64(3), "type": "audio"}}, rm, "", reflect.TypeOf((*testCreation)(nil)).Elem())
+	assert.EqualError(t, err, "unknown discriminator type: audio")
+}
+
+func TestListMapped(t *testing.T) {
+	db := &testDB{rows: testRows{
+		columns: []string{"id", "type", "duration", "w"},
+		values: [][]driver.Value{
+			{int64(1), []byte("video"), int64(30), nil},
+			{int64(2), []byte("picture"), nil, int64(640)},
+		},
+	}}
+	statement := sql.Prepare(sql.NewContext(), sql.Frag(`SELECT * FROM creation`))
+	rm := &ResultMap{
+		ID: "id",
+		Discriminator: &Discriminator{Column: "type", Cases: map[string]Case{
+			"video":   CaseOf[*testVideo](nil),
+			"picture": CaseOf[testPicture](&ResultMap{ID: "id", Columns: map[string]string{"w": "Width"}}),
+		}},
+	}
+	list, err := ListMapped[testCreation](Set(context.Background(), db), statement, rm)
+	if assert.NoError(t, err) && assert.Len(t, list, 2) {
+		assert.Equal(t, &testVideo{ID: 1, Duration: 30}, list[0])
+		assert.Equal(t, testPicture{ID: 2, Width: 640}, list[1])
+	}
+
+	// 目标为接口但没有具体类型时返回错误而不是panic
+	for _, rm := range []*ResultMap{
+		{ID: "id"},
+		{ID: "id", Discriminator: &Discriminator{Column: "type", Cases: map[string]Case{"video": {}, "picture": {}}}},
+	} {
+		assert.NotPanics(t, func() {
+			_, err = ListMapped[testCreation](Set(context.Background(), db), statement, rm)
+		})
+		assert.EqualError(t, err, "invalid result map on implement.testCreation: interface requires a discriminator case with a concrete type")
+	}
+}
@@ -0,0 +1,78 @@
+package implement
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/non1996/go-batis/errors"
+	"github.com/non1996/go-batis/sql"
+)
+
+// SeekResult keyset分页查询结果，Next 为下一页的游标，没有下一页时为空
+type SeekResult[T any] struct {
+	Items []T    `json:"items"`
+	Next  string `json:"next,omitempty"`
+}
+
+// Seek 执行含 sql.Keyset 元素的列表查询，并由最后一行的排序键值生成下一页游标。
+// 排序键值按 db 标签或字段名（不区分大小写）从结果中读取，返回条数少于每页条数时没有下一页
+func Seek[T any](
+	session Session,
+	statement *sql.Prepared,
+	keyset *sql.Keyset,
+) (res *SeekResult[T], err error) {
+	stmt, args, page, err := statement.PreparePage(session)
+	if err != nil {
+		return nil, err
+	}
+
+	res = &SeekResult[T]{}
+	err = getR(session).SelectContext(session, &res.Items, stmt, args...)
+	if err != nil && !NoRow(err) {
+		return nil, err
+	}
+	if len(res.Items) == 0 || int64(len(res.Items)) < page.Size {
+		return res, nil
+	}
+
+	last := reflect.ValueOf(res.Items[len(res.Items)-1])
+	values := make([]any, 0, len(keyset.Keys))
+	for _, key := range keyset.Keys {
+		v, ok := fieldValue(last, key.FieldName())
+		if !ok {
+			return nil, errors.MissingField(fmt.Sprintf("%T", res.Items[0]), key.FieldName())
+		}
+		values = append(values, v)
+	}
+	res.Next, err = sql.EncodeCursor(values...)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// fieldValue 按 sql.StructField 的规则读取结构体字段，支持指针及 map[string]T
+func fieldValue(v reflect.Value, name string) (any, bool) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() == reflect.Map {
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		value := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+		if !value.IsValid() {
+			return nil, false
+		}
+		return value.Interface(), true
+	}
+	f, ok := sql.StructField(v, name)
+	if !ok {
+		return nil, false
+	}
+	return f.Interface(), true
+}
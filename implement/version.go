@@ -0,0 +1,45 @@
+package implement
+
+import (
+	"fmt"
+)
+
+// Versioned 带乐观锁版本号的实体
+type Versioned interface {
+	GetVersion() int64
+	SetVersion(int64)
+}
+
+// ErrOptimisticLock 按版本号更新时没有行被更新，即数据已被其他请求修改或已删除
+type ErrOptimisticLock struct {
+	Version int64 // 更新时使用的版本号
+}
+
+func (e *ErrOptimisticLock) Error() string {
+	return fmt.Sprintf("optimistic lock conflict at version %d", e.Version)
+}
+
+// ExecExpect 执行含 sql.Version 的更新语句，没有行被更新时返回 *ErrOptimisticLock，
+// 成功时递增实体的版本号，entity 为nil时只检查更新行数
+func ExecExpect(
+	session Session,
+	statement Statement,
+	entity Versioned,
+) (affected int64, err error) {
+	affected, err = Exec(session, statement)
+	if err != nil {
+		return 0, err
+	}
+
+	var version int64
+	if entity != nil {
+		version = entity.GetVersion()
+	}
+	if affected == 0 {
+		return 0, &ErrOptimisticLock{Version: version}
+	}
+	if entity != nil {
+		entity.SetVersion(version + 1)
+	}
+	return affected, nil
+}
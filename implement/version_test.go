@@ -0,0 +1,46 @@
+package implement
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/non1996/go-batis/sql"
+)
+
+type testVersioned struct {
+	ID      int64
+	Version int64
+}
+
+func (e *testVersioned) GetVersion() int64  { return e.Version }
+func (e *testVersioned) SetVersion(v int64) { e.Version = v }
+
+func TestExecExpect(t *testing.T) {
+	entity := &testVersioned{ID: 1, Version: 3}
+	update := sql.Prepare(sql.NewContext().WithParams(sql.MapParameters{"id": entity.ID, "version": entity.Version}),
+		sql.Version("version", "version").Update(sql.Frag(`UPDATE gc_image SET state = 1 WHERE id = #{id}`)))
+
+	// 成功时递增版本号
+	db := &testDB{results: []testResult{{affected: 1}}}
+	affected, err := ExecExpect(Set(context.Background(), db), update, entity)
+	if assert.NoError(t, err) && assert.Len(t, db.execs, 1) {
+		assert.Equal(t, int64(1), affected)
+		assert.Equal(t, int64(4), entity.Version)
+		assert.Equal(t, `UPDATE gc_image SET state = 1, version = version + 1 WHERE id = ? AND version = ?`, db.execs[0].query)
+		assert.Equal(t, []any{int64(1), int64(3)}, db.execs[0].args)
+	}
+
+	// 没有行被更新时返回 ErrOptimisticLock，不修改版本号
+	db = &testDB{results: []testResult{{affected: 0}}}
+	_, err = ExecExpect(Set(context.Background(), db), update, entity)
+	var conflict *ErrOptimisticLock
+	if assert.ErrorAs(t, err, &conflict) {
+		assert.Equal(t, int64(4), conflict.Version)
+	}
+	assert.Equal(t, int64(4), entity.Version)
+
+	_, err = ExecExpect(Set(context.Background(), &testDB{}), update, nil)
+	assert.ErrorAs(t, err, &conflict)
+}
@@ -0,0 +1,137 @@
+package sql
+
+import (
+	"strings"
+
+	"github.com/non1996/go-batis/errors"
+)
+
+// MaxParams 方言单条语句可绑定的参数个数上限，SQL Server 单次请求上限2100，其中2个为 sp_executesql 自身使用
+func (d Dialect) MaxParams() int {
+	switch d {
+	case SQLServer:
+		return 2098
+	case SQLite:
+		return 32766
+	}
+	return 65535
+}
+
+// MaxRows 方言单条 VALUES 可插入的行数上限，0 表示不限制；Oracle 不支持多行 VALUES，每条语句插入一行
+func (d Dialect) MaxRows() int {
+	switch d {
+	case SQLServer:
+		return 1000
+	case Oracle:
+		return 1
+	}
+	return 0
+}
+
+// BatchInsert 由单行具名插入语句 INSERT INTO t (a, b) VALUES (:a, :b) 生成的多行插入语句
+type BatchInsert struct {
+	Names []string // 每行的参数名，按出现顺序
+
+	head string // VALUES 及之前的部分
+	row  string // 一行的值，参数替换为 ?
+	tail string // 值之后的部分，不能含参数
+}
+
+// ParseBatchInsert 解析单行具名插入语句
+func ParseBatchInsert(stmt string) (*BatchInsert, error) {
+	tokens := tokenize(stmt)
+	values := -1
+	for i, t := range tokens {
+		if t.is(tokWord, "VALUES") {
+			values = i
+			break
+		}
+	}
+	if values < 0 {
+		return nil, errors.UnsupportedStatement(stmt, "missing VALUES")
+	}
+
+	start := values + 1
+	for start < len(tokens) && tokens[start].kind == tokSpace {
+		start++
+	}
+	if start == len(tokens) || !tokens[start].is(tokPunct, "(") {
+		return nil, errors.UnsupportedStatement(stmt, "missing values row")
+	}
+
+	b := &BatchInsert{}
+	var head, row, tail strings.Builder
+	for _, t := range tokens[:start] {
+		head.WriteString(t.text)
+	}
+	depth, end := 0, -1
+	for i := start; i < len(tokens) && end < 0; i++ {
+		t := tokens[i]
+		switch {
+		case t.is(tokPunct, "("):
+			depth++
+		case t.is(tokPunct, ")"):
+			depth--
+			if depth == 0 {
+				end = i
+			}
+		case t.kind == tokPlaceholder:
+			if !strings.HasPrefix(t.text, ":") {
+				return nil, errors.UnsupportedStatement(stmt, "expect named parameter, got "+t.text)
+			}
+			b.Names = append(b.Names, t.text[1:])
+			row.WriteString("?")
+			continue
+		}
+		row.WriteString(t.text)
+	}
+	if end < 0 {
+		return nil, errors.UnsupportedStatement(stmt, "unclosed values row")
+	}
+	for _, t := range tokens[end+1:] {
+		if t.kind == tokPlaceholder {
+			return nil, errors.UnsupportedStatement(stmt, "parameter after values row")
+		}
+		tail.WriteString(t.text)
+	}
+
+	b.head, b.row, b.tail = head.String(), row.String(), tail.String()
+	return b, nil
+}
+
+// HasTail 值之后是否还有 ON DUPLICATE KEY、ON CONFLICT、RETURNING 等部分
+func (b *BatchInsert) HasTail() bool {
+	return strings.TrimSpace(b.tail) != ""
+}
+
+// ChunkSize 单条语句插入的行数，不超过 maxRows（为0时不限制）及方言的参数个数、行数上限
+func (b *BatchInsert) ChunkSize(dialect Dialect, maxRows int) int {
+	size := dialect.MaxParams()
+	if len(b.Names) != 0 {
+		size /= len(b.Names)
+	}
+	if limit := dialect.MaxRows(); limit > 0 && limit < size {
+		size = limit
+	}
+	if maxRows > 0 && maxRows < size {
+		size = maxRows
+	}
+	return max(size, 1)
+}
+
+// Render 插入rows行的语句，按方言输出占位符
+func (b *BatchInsert) Render(rows int, dialect Dialect) string {
+	var s strings.Builder
+	s.WriteString(b.head)
+	for i := 0; i < rows; i++ {
+		if i != 0 {
+			s.WriteString(", ")
+		}
+		s.WriteString(b.row)
+	}
+	s.WriteString(b.tail)
+	if !numbered(dialect) {
+		return s.String()
+	}
+	return bindPlaceholders(s.String(), nil, dialect)
+}
@@ -0,0 +1,36 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchInsert(t *testing.T) {
+	batch, err := ParseBatchInsert(`INSERT INTO gc_image (md5, name, created_at) VALUES (:md5, LOWER(:name), NOW()) ON DUPLICATE KEY UPDATE name = VALUES(name)`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"md5", "name"}, batch.Names)
+	assert.True(t, batch.HasTail())
+	assert.Equal(t, `INSERT INTO gc_image (md5, name, created_at) VALUES (?, LOWER(?), NOW()), (?, LOWER(?), NOW()) ON DUPLICATE KEY UPDATE name = VALUES(name)`,
+		batch.Render(2, MySQL))
+	assert.Equal(t, `INSERT INTO gc_image (md5, name, created_at) VALUES ($1, LOWER($2), NOW()), ($3, LOWER($4), NOW()) ON DUPLICATE KEY UPDATE name = VALUES(name)`,
+		batch.Render(2, PostgreSQL))
+
+	assert.Equal(t, 100, batch.ChunkSize(MySQL, 100))
+	assert.Equal(t, 32767, batch.ChunkSize(MySQL, 0))
+	assert.Equal(t, 1000, batch.ChunkSize(SQLServer, 0))
+	assert.Equal(t, 1, batch.ChunkSize(Oracle, 100))
+
+	batch, err = ParseBatchInsert(`INSERT INTO gc_image (md5, name, size) VALUES (:md5, :name, :size)`)
+	if assert.NoError(t, err) {
+		assert.False(t, batch.HasTail())
+		assert.Equal(t, 699, batch.ChunkSize(SQLServer, 0))
+	}
+
+	_, err = ParseBatchInsert(`INSERT INTO gc_image (md5) VALUES (?)`)
+	assert.Error(t, err)
+	_, err = ParseBatchInsert(`INSERT INTO gc_image (md5) SELECT md5 FROM t`)
+	assert.Error(t, err)
+}
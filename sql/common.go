@@ -4,8 +4,6 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
-
-	"github.com/non1996/go-jsonobj/container"
 )
 
 var (
@@ -14,26 +12,24 @@ var (
 )
 
 func translateProps(stmt string) (string, []string) {
-	var gIdx int
-	var propMap = container.NewOrderedMap[string, int]()
+	var props []string
+	var propIdx = map[string]int{}
 
 	stmt = regexProp.ReplaceAllStringFunc(stmt, func(s string) string {
 		s = strings.TrimSpace(s)
 		s = strings.Trim(s, "${}")
 		s = strings.TrimSpace(s)
 
-		var idx int
-		if !propMap.Exist(s) {
-			idx = gIdx
-			propMap.Add(s, idx)
-			gIdx++
-		} else {
-			idx = propMap.Get(s)
+		idx, exist := propIdx[s]
+		if !exist {
+			idx = len(props)
+			propIdx[s] = idx
+			props = append(props, s)
 		}
 		return fmt.Sprintf("${%d}", idx)
 	})
 
-	return stmt, propMap.Keys()
+	return stmt, props
 }
 
 func translateParams(stmt string) (string, []string) {
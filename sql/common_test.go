@@ -0,0 +1,28 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslateProps(t *testing.T) {
+	stmt, props := translateProps(`${a} ${b} ${a} ${c}`)
+	assert.Equal(t, `${0} ${1} ${0} ${2}`, stmt)
+	assert.Equal(t, []string{"a", "b", "c"}, props)
+
+	// 多个不同的prop按出现顺序替换
+	for i := 0; i < 20; i++ {
+		statement, err := Frag(`SELECT ${a} FROM ${b} WHERE ${c} = 1`).Evaluate(NewContext().WithParams(MapParameters{"a": "id", "b": "gc_image", "c": "state"}))
+		if assert.NoError(t, err) {
+			assert.Equal(t, `SELECT id FROM gc_image WHERE state = 1`, statement.Stmt)
+		}
+	}
+}
+
+func TestNamedParams(t *testing.T) {
+	statement, err := Frag(`UPDATE gc_image SET md5 = #{md5} WHERE id = #{id}`).Evaluate(NewContext().Named())
+	if assert.NoError(t, err) {
+		assert.Equal(t, `UPDATE gc_image SET md5 = :md5 WHERE id = :id`, statement.Stmt)
+	}
+}
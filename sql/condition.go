@@ -3,7 +3,9 @@ package sql
 import (
 	"bytes"
 	"fmt"
+	"slices"
 	"text/template"
+	"text/template/parse"
 
 	"github.com/non1996/go-batis/errors"
 )
@@ -20,29 +22,143 @@ func (t *ConstCondition) Satisfy(ctx *Context) (satisfy bool, err error) {
 	return t.b, nil
 }
 
+func (t *ConstCondition) String() string {
+	return fmt.Sprintf("%t", t.b)
+}
+
 const tmplCondition = `{{if %s}}t{{else}}f{{end}}`
 
+// conditionString 条件的文本描述，用于追踪、静态分析等场景
+func conditionString(c Condition) string {
+	if s, ok := c.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", c)
+}
+
 // templateCondition 使用go template实现的条件语句
 type templateCondition struct {
-	tmpl *template.Template
+	cond   string
+	tmpl   *template.Template
+	fields []string // 条件中引用的参数名
 }
 
 func Test(cond string) Condition {
-	return &templateCondition{
-		tmpl: template.Must(template.New("").Parse(fmt.Sprintf(tmplCondition, cond))),
+	c, err := ParseTest(cond)
+	if err != nil {
+		panic(err)
 	}
+	return c
+}
+
+// ParseTest 解析条件表达式，表达式不合法时返回错误，用于解析外部输入如mapper文件
+func ParseTest(cond string) (Condition, error) {
+	tmpl, err := template.New("").Parse(fmt.Sprintf(tmplCondition, cond))
+	if err != nil {
+		return nil, err
+	}
+	return &templateCondition{
+		cond:   cond,
+		tmpl:   tmpl,
+		fields: templateFields(tmpl.Tree.Root),
+	}, nil
 }
 
 func (t *templateCondition) Satisfy(ctx *Context) (satisfy bool, err error) {
+	switch params := ctx.params.(type) {
+	case MapParameters:
+		if t.covered(params) {
+			return t.execute(params)
+		}
+	case *scopeParameters:
+		if view := params.view(); t.covered(view) {
+			return t.execute(view)
+		}
+	default:
+		// 自定义的 Parameters 原样传入，模板中可调用其方法、使用 $.x、range/with 等；
+		// 无法直接求值（如字段未导出、引用 context.Context 中的值）时按参数名查找
+		if satisfy, err = t.execute(params); err == nil {
+			return satisfy, nil
+		}
+	}
+	return t.execute(t.data(ctx))
+}
+
+func (t *templateCondition) execute(data any) (bool, error) {
 	b := bytes.NewBuffer(make([]byte, 0, 1))
-	err = t.tmpl.Execute(b, ctx.params)
-	if err != nil {
+	if err := t.tmpl.Execute(b, data); err != nil {
 		return false, errors.TmplExecute(err)
 	}
-
 	return bytes.Equal(b.Bytes(), []byte("t")), nil
 }
 
+// data 按条件引用的参数名从上下文中查找，构造仅包含这些参数的map，
+// 用于分层参数、引用了 context.Context 中的值等无法直接作为模板数据的情况
+func (t *templateCondition) data(ctx *Context) map[string]any {
+	data := make(map[string]any, len(t.fields))
+	for _, name := range t.fields {
+		if value, exist := ctx.Lookup(name); exist {
+			data[name] = value
+		}
+	}
+	return data
+}
+
+func (t *templateCondition) covered(m MapParameters) bool {
+	for _, name := range t.fields {
+		if _, exist := m[name]; !exist {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *templateCondition) String() string {
+	return t.cond
+}
+
+// templateFields 收集模板中以 .name 形式引用的参数名
+func templateFields(node parse.Node) []string {
+	var fields []string
+	var walk func(parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.ChainNode:
+			walk(n.Node)
+		case *parse.FieldNode:
+			if !slices.Contains(fields, n.Ident[0]) {
+				fields = append(fields, n.Ident[0])
+			}
+		}
+	}
+	walk(node)
+	return fields
+}
+
 var tc = &trueCondition{}
 
 type trueCondition struct {
@@ -55,3 +171,7 @@ func True() Condition {
 func (c *trueCondition) Satisfy(ctx *Context) (bool, error) {
 	return true, nil
 }
+
+func (c *trueCondition) String() string {
+	return "true"
+}
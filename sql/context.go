@@ -1,9 +1,19 @@
 package sql
 
 import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
 	"github.com/non1996/go-batis/errors"
 )
 
+// ParamKey context.Context 中可作为参数被引用的值的键，如租户id
+//
+//	ctx = context.WithValue(ctx, sql.ParamKey("tenantID"), 42)
+type ParamKey string
+
 var (
 	emptyParam      = MapParameters{}
 	emptyCollection = Collection{}
@@ -13,7 +23,18 @@ var (
 type Context struct {
 	params     Parameters
 	named      bool
-	collection Collection
+	collection Definitions
+	namespace  string // 当前求值的sql定义所在的命名空间，用于解析相对引用
+	tracer     *tracer
+	format     Format
+	goContext  context.Context
+	properties Properties
+	dialect    Dialect
+	counting   bool          // 求值计数语句，分页等元素不输出
+	page       *PageRequest  // 记录分页元素的分页参数
+	seq        *atomic.Int64 // 推导参数的序号，同一次求值中共享
+	handlers   *TypeHandlers
+	scripts    *Scripts
 }
 
 func NewContext() *Context {
@@ -21,6 +42,7 @@ func NewContext() *Context {
 		params:     emptyParam,
 		named:      false,
 		collection: emptyCollection,
+		seq:        &atomic.Int64{},
 	}
 }
 
@@ -39,21 +61,210 @@ func (c *Context) WithCollection(collection Collection) *Context {
 	return c
 }
 
+// WithRegistry 从注册表中查找被引用的sql定义
+func (c *Context) WithRegistry(registry *Registry) *Context {
+	c.collection = registry
+	return c
+}
+
+// WithDefinitions 从任意sql定义来源中查找被引用的sql定义，如 PropertyCollection
+func (c *Context) WithDefinitions(defs Definitions) *Context {
+	c.collection = defs
+	return c
+}
+
+// WithNamespace 设置当前命名空间，其中不含命名空间的引用优先解析为该命名空间下的定义
+func (c *Context) WithNamespace(namespace string) *Context {
+	c.namespace = namespace
+	return c
+}
+
+// WithContext 设置请求的 context.Context，求值过程中检查其是否取消，
+// 自定义条件等可通过 Context 方法读取，其中以 ParamKey 为键的值可作为参数被引用
+func (c *Context) WithContext(ctx context.Context) *Context {
+	c.goContext = ctx
+	return c
+}
+
+// Context 返回请求的 context.Context，未设置时返回 context.Background()
+func (c *Context) Context() context.Context {
+	if c.goContext == nil {
+		return context.Background()
+	}
+	return c.goContext
+}
+
+// Lookup 查找参数值，参数中不存在时查找 context.Context 中以 ParamKey(name) 为键的值，
+// _databaseId 为当前方言
+func (c *Context) Lookup(name string) (any, bool) {
+	if c.params.Exist(name) {
+		return c.params.Get(name), true
+	}
+	if name == paramDialect && c.dialect != "" {
+		return string(c.dialect), true
+	}
+	if c.goContext != nil {
+		if value := c.goContext.Value(ParamKey(name)); value != nil {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// WithProperties 设置全局配置属性，片段中的 ${} 在参数中找不到时使用
+func (c *Context) WithProperties(properties Properties) *Context {
+	c.properties = properties
+	return c
+}
+
+// WithDialect 设置数据库方言，用于选择 Variants 中的定义，条件中可通过 ._databaseId 引用
+func (c *Context) WithDialect(dialect Dialect) *Context {
+	c.dialect = dialect
+	return c
+}
+
+// Dialect 返回数据库方言
+func (c *Context) Dialect() Dialect {
+	return c.dialect
+}
+
+// WithTypeHandlers 设置解析参数时使用的类型处理器，未设置时使用 DefaultTypeHandlers
+func (c *Context) WithTypeHandlers(handlers *TypeHandlers) *Context {
+	c.handlers = handlers
+	return c
+}
+
+func (c *Context) typeHandlers() *TypeHandlers {
+	if c.handlers == nil {
+		return DefaultTypeHandlers
+	}
+	return c.handlers
+}
+
+// WithFormat 设置 Evaluate 输出sql的格式
+func (c *Context) WithFormat(format Format) *Context {
+	c.format = format
+	return c
+}
+
+// WithTrace 开启求值追踪，记录每个元素的条件、引用及生成的sql，通过 Trace 获取
+func (c *Context) WithTrace() *Context {
+	c.tracer = &tracer{}
+	return c
+}
+
+// Trace 返回求值追踪树，未开启追踪时返回nil
+func (c *Context) Trace() *Trace {
+	if c.tracer == nil {
+		return nil
+	}
+	return &c.tracer.trace
+}
+
 func (c *Context) GetSQL(id string) SQL {
-	return c.collection.MustGet(id)
+	_, sql := c.mustResolve(id)
+	return sql
+}
+
+// resolve 按当前命名空间解析id，返回完整id及sql定义
+func (c *Context) resolve(id string) (string, SQL, error) {
+	fullID, sql, exist := resolveID(c.collection, c.namespace, id)
+	if !exist {
+		return "", nil, errors.MissingSQL(id)
+	}
+	return fullID, sql, nil
+}
+
+func (c *Context) mustResolve(id string) (string, SQL) {
+	fullID, sql, err := c.resolve(id)
+	if err != nil {
+		panic(err)
+	}
+	return fullID, sql
 }
 
 func (c *Context) Next(params Parameters) *Context {
 	return &Context{
-		params:     MergeParameters(c.params, params),
+		params:     Scope(c.params, params),
 		named:      c.named,
 		collection: c.collection,
+		namespace:  c.namespace,
+		tracer:     c.tracer,
+		format:     c.format,
+		goContext:  c.goContext,
+		properties: c.properties,
+		dialect:    c.dialect,
+		counting:   c.counting,
+		page:       c.page,
+		seq:        c.seq,
+		handlers:   c.handlers,
+		scripts:    c.scripts,
+	}
+}
+
+// bind 为元素推导出的参数值生成参数名，值记录在语句的 Bindings 中
+func (c *Context) bind(statement *Statement, value any) string {
+	if c.seq == nil {
+		c.seq = &atomic.Int64{}
+	}
+	name := fmt.Sprintf("__gobatis_%d", c.seq.Add(1))
+	if statement.Bindings == nil {
+		statement.Bindings = MapParameters{}
+	}
+	statement.Bindings[name] = value
+	statement.ArgNames = append(statement.ArgNames, name)
+	return name
+}
+
+// Evaluate 对sql元素求值，并按上下文设置的格式输出
+func (c *Context) Evaluate(e Elem) (*Statement, error) {
+	statement, err := e.Evaluate(c)
+	if err != nil {
+		return nil, err
+	}
+	if c.format == FormatNone {
+		return statement, nil
+	}
+	return &Statement{
+		Stmt:     c.format.Apply(statement.Stmt),
+		ArgNames: statement.ArgNames,
+		Bindings: statement.Bindings,
+	}, nil
+}
+
+// Definitions sql定义的来源，Collection 与 Registry 均实现了该接口
+type Definitions interface {
+	Lookup(id string) (SQL, bool)
+}
+
+// resolveID 先在命名空间内查找相对id，再按完整id查找
+func resolveID(defs Definitions, namespace, id string) (string, SQL, bool) {
+	if namespace != "" {
+		fullID := namespace + "." + id
+		if sql, exist := defs.Lookup(fullID); exist {
+			return fullID, sql, true
+		}
 	}
+	sql, exist := defs.Lookup(id)
+	return id, sql, exist
+}
+
+// namespaceOf 完整id中最后一个 . 之前的部分
+func namespaceOf(id string) string {
+	if idx := strings.LastIndexByte(id, '.'); idx >= 0 {
+		return id[:idx]
+	}
+	return ""
 }
 
 // Collection sql定义集合
 type Collection map[string]SQL
 
+func (c Collection) Lookup(id string) (SQL, bool) {
+	sql, exist := c[id]
+	return sql, exist
+}
+
 func (c Collection) MustGet(id string) SQL {
 	sql, exist := c[id]
 	if !exist {
@@ -0,0 +1,104 @@
+package sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext(t *testing.T) {
+	e := Composite(
+		`SELECT * FROM gc_image`,
+		Where(
+			If(Test(".tenantID"), `tenant_id = #{tenantID}`),
+			If(Test(".state"), `AND state = #{state}`),
+		),
+	)
+
+	// context.Context 中的值可被条件及参数引用
+	{
+		goCtx := context.WithValue(context.Background(), ParamKey("tenantID"), 42)
+		ctx := NewContext().
+			WithParams(MapParameters{"state": 1}).
+			WithContext(goCtx)
+		stmt, args, err := Prepare(ctx, e).Prepare()
+		if assert.NoError(t, err) {
+			assert.Equal(t, `SELECT * FROM gc_image WHERE tenant_id = ? AND state = ?`, stmt)
+			assert.Equal(t, []any{42, 1}, args)
+		}
+	}
+	// 非map参数
+	{
+		ctx := NewContext().WithParams(stateParameters{state: 1})
+		stmt, err := e.Evaluate(ctx)
+		if assert.NoError(t, err) {
+			assert.Equal(t, `SELECT * FROM gc_image WHERE state = ?`, stmt.GetStmt())
+		}
+	}
+	// 取消
+	{
+		goCtx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, _, err := Prepare(NewContext(), e).PrepareContext(goCtx)
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+}
+
+type stateParameters struct {
+	state int
+}
+
+func (p stateParameters) Get(key string) any {
+	return p.state
+}
+
+func (p stateParameters) Exist(key string) bool {
+	return key == "state"
+}
+
+func (p stateParameters) Keys() []string {
+	return []string{"state"}
+}
+
+// searchParameters 自定义参数，条件中可调用其方法
+type searchParameters struct {
+	States []int
+}
+
+func (p *searchParameters) Get(key string) any {
+	if key == "states" {
+		return p.States
+	}
+	return nil
+}
+
+func (p *searchParameters) Exist(key string) bool {
+	return key == "states"
+}
+
+func (p *searchParameters) Keys() []string {
+	return []string{"states"}
+}
+
+func (p *searchParameters) Filtered() bool {
+	return len(p.States) != 0
+}
+
+func TestCustomParameters(t *testing.T) {
+	e := Composite(`SELECT * FROM gc_image`, Where(
+		If(Test(".Filtered"), `state IN (#{states})`),
+		If(Test(`and (gt (len $.States) 1) (eq (index .States 0) 1)`), `AND featured = 1`),
+	))
+
+	stmt, args, err := Prepare(NewContext().WithParams(&searchParameters{States: []int{1, 2}}), e).Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM gc_image WHERE state IN (?, ?) AND featured = 1`, stmt)
+		assert.Equal(t, []any{1, 2}, args)
+	}
+
+	stmt, _, err = Prepare(NewContext().WithParams(&searchParameters{}).WithFormat(FormatCompact), e).Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM gc_image`, stmt)
+	}
+}
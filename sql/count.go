@@ -0,0 +1,178 @@
+package sql
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Count 由查询语句派生计数语句，参数与原查询一致，派生时不输出分页等元素。
+// 简单查询改写选择列表为 COUNT(*) 并去掉 ORDER BY/LIMIT/OFFSET/FETCH，
+// 含 DISTINCT、GROUP BY、集合运算、聚合函数等无法安全改写的查询包装为 SELECT COUNT(*) FROM (...) t，
+// 包装时去掉不影响结果行数的顶层 ORDER BY，SQL Server 不允许派生表中单独使用 ORDER BY
+func Count(e Elem) Elem {
+	return &count{Query: e}
+}
+
+type count struct {
+	Query Elem
+}
+
+func (s *count) Evaluate(ctx *Context) (statement *Statement, err error) {
+	node, err := ctx.enter(s)
+	defer func() { ctx.leave(node, statement, err) }()
+	if err != nil {
+		return nil, err
+	}
+
+	c := *ctx
+	c.counting = true
+	query, err := s.Query.Evaluate(&c)
+	if err != nil {
+		return nil, err
+	}
+	if rewritten, ok := rewriteCount(query); ok {
+		return rewritten, nil
+	}
+	query = trimOrderBy(query)
+	return &Statement{
+		Stmt:     "SELECT COUNT(*) FROM (" + strings.TrimSpace(query.Stmt) + ") t",
+		ArgNames: query.ArgNames,
+		Bindings: query.Bindings,
+	}, nil
+}
+
+var (
+	// countUnsafe 出现在查询顶层时无法改写计数的关键字
+	countUnsafe = []string{"DISTINCT", "TOP", "GROUP", "HAVING", "UNION", "INTERSECT", "EXCEPT", "MINUS", "WINDOW", "INTO", "FOR"}
+	// countTail 计数时可去掉的尾部子句
+	countTail = []string{"ORDER", "LIMIT", "OFFSET", "FETCH"}
+	// aggregates 选择列表中出现时无法改写计数的函数
+	aggregates = []string{"COUNT", "SUM", "AVG", "MIN", "MAX", "GROUP_CONCAT", "STRING_AGG", "ARRAY_AGG", "LISTAGG", "OVER"}
+)
+
+// rewriteCount 将 SELECT ... FROM ... 改写为 SELECT COUNT(*) FROM ...，去掉尾部的排序及分页子句，
+// 并去掉被删除部分中的参数
+func rewriteCount(query *Statement) (*Statement, bool) {
+	tokens := tokenize(query.Stmt)
+	from, cut := -1, len(tokens)
+	depth, first := 0, true
+	for i, t := range tokens {
+		if t.kind == tokSpace || t.kind == tokComment {
+			continue
+		}
+		if first {
+			if !t.is(tokWord, "SELECT") {
+				return nil, false
+			}
+			first = false
+			continue
+		}
+		switch {
+		case t.is(tokPunct, "("):
+			depth++
+		case t.is(tokPunct, ")"):
+			depth--
+		case t.kind != tokWord:
+		case from < 0 && slices.Contains(aggregates, t.upper()):
+			return nil, false
+		case depth != 0:
+		case slices.Contains(countUnsafe, t.upper()):
+			return nil, false
+		case from < 0 && t.upper() == "FROM":
+			from = i
+		case from >= 0 && cut == len(tokens) && slices.Contains(countTail, t.upper()):
+			cut = i
+		}
+	}
+	if from < 0 {
+		return nil, false
+	}
+
+	// 按占位符顺序保留 FROM 至尾部子句之间的参数
+	var argNames []string
+	idx := 0
+	for i, t := range tokens {
+		if t.kind != tokPlaceholder {
+			continue
+		}
+		if i > from && i < cut && idx < len(query.ArgNames) {
+			argNames = append(argNames, query.ArgNames[idx])
+		}
+		idx++
+	}
+	if idx != len(query.ArgNames) {
+		return nil, false
+	}
+
+	var b strings.Builder
+	b.WriteString("SELECT COUNT(*) ")
+	for _, t := range tokens[from:cut] {
+		b.WriteString(t.text)
+	}
+	return &Statement{
+		Stmt:     strings.TrimSpace(b.String()),
+		ArgNames: argNames,
+		Bindings: query.Bindings,
+	}, true
+}
+
+// trimOrderBy 去掉顶层的 ORDER BY 子句及其中的参数，查询含 LIMIT/OFFSET/FETCH/TOP 时排序影响结果，保持不变
+func trimOrderBy(query *Statement) *Statement {
+	tokens := tokenize(query.Stmt)
+	order, end := -1, len(tokens)
+	depth := 0
+	for i, t := range tokens {
+		switch {
+		case t.is(tokPunct, "("):
+			depth++
+		case t.is(tokPunct, ")"):
+			depth--
+		case t.kind != tokWord || depth != 0:
+		case slices.Contains([]string{"LIMIT", "OFFSET", "FETCH", "TOP"}, t.upper()):
+			return query
+		case t.upper() == "ORDER":
+			order, end = i, len(tokens)
+		case order >= 0 && t.upper() == "FOR":
+			end = i
+		}
+	}
+	if order < 0 {
+		return query
+	}
+
+	var b strings.Builder
+	var argNames []string
+	idx := 0
+	for i, t := range tokens {
+		if t.kind == tokPlaceholder {
+			if (i < order || i >= end) && idx < len(query.ArgNames) {
+				argNames = append(argNames, query.ArgNames[idx])
+			}
+			idx++
+		}
+		if i < order || i >= end {
+			b.WriteString(t.text)
+		}
+	}
+	if idx != len(query.ArgNames) {
+		return query
+	}
+	return &Statement{
+		Stmt:     strings.TrimSpace(b.String()),
+		ArgNames: argNames,
+		Bindings: query.Bindings,
+	}
+}
+
+func (s *count) inspect(w *walker) error {
+	return w.walk(s.Query)
+}
+
+func (s *count) goSource() (string, error) {
+	src, err := GoSource(s.Query)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sql.Count(%s)", src), nil
+}
@@ -0,0 +1,53 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCount(t *testing.T) {
+	params := MapParameters{"md5": "abc", "alias": "gi", "size": 10, "kw": "%a%"}
+	render := func(e Elem) (string, []any) {
+		stmt, args, err := Prepare(NewContext().WithParams(params), Count(e)).Prepare()
+		assert.NoError(t, err)
+		return stmt, args
+	}
+
+	// 改写选择列表，去掉排序及分页子句中的参数
+	stmt, args := render(Composite(
+		`SELECT gi.id, IF(gi.name LIKE #{kw}, 1, 0) AS matched, (SELECT name FROM gc_user u WHERE u.id = gi.uid) AS owner FROM gc_image gi`,
+		Where(If(Test(".md5"), `AND gi.md5 = #{md5}`)),
+		`ORDER BY gi.id DESC LIMIT #{size}`,
+	))
+	assert.Equal(t, `SELECT COUNT(*) FROM gc_image gi WHERE gi.md5 = ?`, stmt)
+	assert.Equal(t, []any{"abc"}, args)
+
+	// 子查询中的 FROM、ORDER BY 不影响改写
+	stmt, _ = render(Frag(`SELECT EXTRACT(YEAR FROM created_at) FROM gc_image WHERE id IN (SELECT id FROM gc_tag ORDER BY id LIMIT 10)`))
+	assert.Equal(t, `SELECT COUNT(*) FROM gc_image WHERE id IN (SELECT id FROM gc_tag ORDER BY id LIMIT 10)`, stmt)
+
+	// 无法安全改写时包装
+	for _, query := range []string{
+		`SELECT DISTINCT md5 FROM gc_image`,
+		`SELECT md5, COUNT(*) FROM gc_image GROUP BY md5`,
+		`SELECT MAX(id) FROM gc_image`,
+		`SELECT id FROM gc_image UNION SELECT id FROM gc_tag`,
+		`WITH t AS (SELECT id FROM gc_image) SELECT id FROM t`,
+	} {
+		stmt, _ = render(Frag(query))
+		assert.Equal(t, `SELECT COUNT(*) FROM (`+query+`) t`, stmt)
+	}
+
+	// 包装时去掉顶层排序，分页子句影响行数时保留
+	stmt, args = render(Frag(`SELECT md5, COUNT(*) FROM gc_image WHERE md5 <> #{md5} GROUP BY md5 ORDER BY FIELD(md5, #{kw})`))
+	assert.Equal(t, `SELECT COUNT(*) FROM (SELECT md5, COUNT(*) FROM gc_image WHERE md5 <> ? GROUP BY md5) t`, stmt)
+	assert.Equal(t, []any{"abc"}, args)
+	stmt, _ = render(Frag(`SELECT DISTINCT md5 FROM gc_image ORDER BY md5 LIMIT 10`))
+	assert.Equal(t, `SELECT COUNT(*) FROM (SELECT DISTINCT md5 FROM gc_image ORDER BY md5 LIMIT 10) t`, stmt)
+
+	src, err := GoSource(Count(Frag(`SELECT * FROM t`)))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "sql.Count(sql.Frag(\"SELECT * FROM t\"))", src)
+	}
+}
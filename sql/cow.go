@@ -0,0 +1,57 @@
+package sql
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// cowMap 写时复制的map，读操作无锁，写操作在锁内复制当前快照、修改后原子替换，
+// 适合在init阶段注册、运行期只读的注册表。零值可用
+type cowMap[K comparable, V any] struct {
+	mu       sync.Mutex // 串行化写操作
+	snapshot atomic.Pointer[map[K]V]
+}
+
+// load 返回当前快照，调用方不可修改
+func (m *cowMap[K, V]) load() map[K]V {
+	if p := m.snapshot.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+func (m *cowMap[K, V]) get(key K) (V, bool) {
+	v, exist := m.load()[key]
+	return v, exist
+}
+
+func (m *cowMap[K, V]) set(key K, value V) {
+	_ = m.update(func(current, next map[K]V) error {
+		next[key] = value
+		return nil
+	})
+}
+
+// update 在写锁内基于当前快照构造新快照，fn返回错误时放弃修改
+func (m *cowMap[K, V]) update(fn func(current, next map[K]V) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current := m.load()
+	next := make(map[K]V, len(current))
+	for k, v := range current {
+		next[k] = v
+	}
+	if err := fn(current, next); err != nil {
+		return err
+	}
+	m.snapshot.Store(&next)
+	return nil
+}
+
+// replace 以next整体替换当前快照，调用后调用方不可再修改next
+func (m *cowMap[K, V]) replace(next map[K]V) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.snapshot.Store(&next)
+}
@@ -0,0 +1,128 @@
+package sql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/non1996/go-batis/errors"
+)
+
+// Dialect 数据库方言，对应mybatis中的databaseId
+type Dialect string
+
+const (
+	MySQL      Dialect = "mysql"
+	PostgreSQL Dialect = "postgresql"
+	SQLite     Dialect = "sqlite"
+	SQLServer  Dialect = "sqlserver"
+	Oracle     Dialect = "oracle"
+)
+
+// paramDialect 条件及参数中引用当前方言的参数名，与mybatis一致
+const paramDialect = "_databaseId"
+
+// Variants 按方言区分的sql定义，求值时选择 Context 方言对应的定义，没有对应定义时使用通用定义fallback，
+// fallback 为nil且没有对应定义时报错
+func Variants(fallback Elem, variants map[Dialect]Elem) SQL {
+	return &dialectVariants{
+		Fallback: fallback,
+		Variants: variants,
+	}
+}
+
+// DialectIs 判断 Context 的方言是否为给定方言之一
+func DialectIs(dialects ...Dialect) Condition {
+	return &dialectCondition{dialects: dialects}
+}
+
+type dialectCondition struct {
+	dialects []Dialect
+}
+
+func (c *dialectCondition) Satisfy(ctx *Context) (bool, error) {
+	for _, d := range c.dialects {
+		if d == ctx.dialect {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *dialectCondition) String() string {
+	names := make([]string, 0, len(c.dialects))
+	for _, d := range c.dialects {
+		names = append(names, string(d))
+	}
+	return fmt.Sprintf("dialect in (%s)", strings.Join(names, ", "))
+}
+
+// dialectVariants 按方言区分的sql定义
+type dialectVariants struct {
+	Fallback Elem
+	Variants map[Dialect]Elem
+}
+
+func (s *dialectVariants) Evaluate(ctx *Context) (statement *Statement, err error) {
+	node, err := ctx.enter(s)
+	defer func() { ctx.leave(node, statement, err) }()
+	if err != nil {
+		return nil, err
+	}
+
+	if e, exist := s.Variants[ctx.dialect]; exist {
+		return e.Evaluate(ctx)
+	}
+	if s.Fallback == nil {
+		return nil, errors.MissingVariant(string(ctx.dialect))
+	}
+	return s.Fallback.Evaluate(ctx)
+}
+
+// dialects 按字典序返回定义了变体的方言
+func (s *dialectVariants) dialects() []Dialect {
+	dialects := make([]Dialect, 0, len(s.Variants))
+	for d := range s.Variants {
+		dialects = append(dialects, d)
+	}
+	sort.Slice(dialects, func(i, j int) bool { return dialects[i] < dialects[j] })
+	return dialects
+}
+
+func (s *dialectVariants) inspect(w *walker) error {
+	dialects := s.dialects()
+	for _, d := range dialects {
+		cond := DialectIs(d)
+		if err := w.guarded(guards(cond), func() error {
+			return w.walk(s.Variants[d])
+		}); err != nil {
+			return err
+		}
+	}
+	if s.Fallback == nil {
+		return nil
+	}
+	return w.guarded([]string{"not (" + conditionString(DialectIs(dialects...)) + ")"}, func() error {
+		return w.walk(s.Fallback)
+	})
+}
+
+func (s *dialectVariants) goSource() (string, error) {
+	fallback := "nil"
+	if s.Fallback != nil {
+		src, err := GoSource(s.Fallback)
+		if err != nil {
+			return "", err
+		}
+		fallback = src
+	}
+	var b strings.Builder
+	for _, d := range s.dialects() {
+		src, err := GoSource(s.Variants[d])
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "\n%q: %s,", d, src)
+	}
+	return fmt.Sprintf("sql.Variants(%s, map[sql.Dialect]sql.Elem{%s\n})", fallback, b.String()), nil
+}
@@ -0,0 +1,65 @@
+package sql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialect(t *testing.T) {
+	m, err := ParseMapper(strings.NewReader(`
+<mapper namespace="Image">
+  <select id="Now" databaseId="sqlserver">SELECT GETDATE()</select>
+  <select id="Now">SELECT NOW()</select>
+  <select id="Flag">
+    SELECT <if test='eq ._databaseId "postgresql"'>TRUE</if><if test='ne ._databaseId "postgresql"'>1</if>
+  </select>
+</mapper>`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	collection, err := Mappers{m}.Collection()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	render := func(dialect Dialect, id string) string {
+		stmt, _, err := PrepareID(NewContext().WithCollection(collection).WithDialect(dialect).WithFormat(FormatCompact), id).Prepare()
+		assert.NoError(t, err)
+		return stmt
+	}
+	assert.Equal(t, `SELECT GETDATE()`, render(SQLServer, "Image.Now"))
+	assert.Equal(t, `SELECT NOW()`, render(MySQL, "Image.Now"))
+	assert.Equal(t, `SELECT TRUE`, render(PostgreSQL, "Image.Flag"))
+	assert.Equal(t, `SELECT 1`, render(MySQL, "Image.Flag"))
+
+	// 条件
+	e := Composite(`SELECT * FROM t`, Choose(
+		When(DialectIs(MySQL, SQLite), `LIMIT 1`),
+		OtherWise(`FETCH FIRST 1 ROWS ONLY`),
+	))
+	stmt, err := e.Evaluate(NewContext().WithDialect(SQLite))
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM t LIMIT 1`, stmt.GetStmt())
+	}
+
+	// 没有通用定义
+	_, err = Variants(nil, map[Dialect]Elem{MySQL: Frag(`SELECT 1`)}).Evaluate(NewContext().WithDialect(Oracle))
+	assert.EqualError(t, err, `missing sql variant for dialect: "oracle"`)
+}
+
+func TestResolvePlaceholders(t *testing.T) {
+	e := Frag(`SELECT * FROM gc_image WHERE meta ?? #{key} AND tags ??| array['a', 'b'] AND note <> 'why?' /* ? */ AND id IN (#{ids})`)
+	params := MapParameters{"key": "w", "ids": []int{1, 2}}
+
+	stmt, args, err := Prepare(NewContext().WithParams(params).WithDialect(PostgreSQL), e).Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM gc_image WHERE meta ? $1 AND tags ?| array['a', 'b'] AND note <> 'why?' /* ? */ AND id IN ($2, $3)`, stmt)
+		assert.Equal(t, []any{"w", 1, 2}, args)
+	}
+
+	// 具名模式下无法传递元素推导出的参数
+	_, _, err = Prepare(NewContext().Named().WithParams(MapParameters{"page": PageRequest{Page: 2, Size: 10}}), Composite(`SELECT * FROM gc_image`, Page("page"))).Prepare()
+	assert.EqualError(t, err, "derived parameters are not supported in named mode")
+}
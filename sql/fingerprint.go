@@ -0,0 +1,79 @@
+package sql
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// Fingerprint 语句形态的指纹，用于按查询形态聚合指标、慢查询日志
+type Fingerprint struct {
+	Hash string // Text 的64位 FNV-1a 哈希，16位十六进制
+	Text string // 规范化的语句
+}
+
+// Fingerprint 规范化后的语句指纹，见 FingerprintOf
+func (s Statement) Fingerprint() Fingerprint {
+	return FingerprintOf(s.Stmt)
+}
+
+// FingerprintOf 规范化语句并计算指纹：去掉注释并统一空白，字面量及各方言的占位符替换为 ?，
+// 展开的 IN 列表折叠为 IN (...)。可用于 Prepare 输出的已解析语句
+func FingerprintOf(stmt string) Fingerprint {
+	var words []string
+	for _, t := range tokenize(stmt) {
+		switch t.kind {
+		case tokSpace, tokComment:
+		case tokString, tokNumber, tokPlaceholder:
+			words = append(words, "?")
+		default:
+			words = append(words, t.text)
+		}
+	}
+	words = foldInLists(words)
+
+	var b strings.Builder
+	for i, w := range words {
+		if i != 0 && spaced(words[i-1], w) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(w)
+	}
+	text := b.String()
+
+	h := fnv.New64a()
+	h.Write([]byte(text))
+	return Fingerprint{Hash: fmt.Sprintf("%016x", h.Sum64()), Text: text}
+}
+
+// foldInLists 将 IN (?, ?, ...) 及空列表展开得到的 IN (NULL) 折叠为 IN (...)
+func foldInLists(words []string) []string {
+	res := make([]string, 0, len(words))
+	for i := 0; i < len(words); i++ {
+		res = append(res, words[i])
+		if !strings.EqualFold(words[i], "IN") || i+1 >= len(words) || words[i+1] != "(" {
+			continue
+		}
+		end := i + 2
+		for end < len(words) && (words[end] == "?" || words[end] == "," || strings.EqualFold(words[end], "NULL")) {
+			end++
+		}
+		if end == i+2 || end >= len(words) || words[end] != ")" {
+			continue
+		}
+		res = append(res, "(", "...", ")")
+		i = end
+	}
+	return res
+}
+
+// spaced 两个相邻token之间是否输出空格
+func spaced(prev, next string) bool {
+	switch {
+	case prev == "(" || prev == "." || prev == "::":
+		return false
+	case next == ")" || next == "," || next == "." || next == "::":
+		return false
+	}
+	return true
+}
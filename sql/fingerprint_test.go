@@ -0,0 +1,28 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprint(t *testing.T) {
+	a := FingerprintOf("SELECT gi.id FROM gc_image gi\n  WHERE gi.md5 = 'abc' -- md5\n  AND gi.id IN (?, ?, ?) LIMIT 10")
+	b := FingerprintOf(`SELECT gi.id FROM gc_image gi WHERE gi.md5 = $1 AND gi.id IN ($2) LIMIT $3`)
+	assert.Equal(t, `SELECT gi.id FROM gc_image gi WHERE gi.md5 = ? AND gi.id IN (...) LIMIT ?`, a.Text)
+	assert.Equal(t, a, b)
+	assert.Len(t, a.Hash, 16)
+
+	// 空列表
+	assert.Equal(t, a, FingerprintOf(`SELECT gi.id FROM gc_image gi WHERE gi.md5 = ? AND gi.id IN (NULL) LIMIT ?`))
+	// 子查询不折叠
+	assert.Equal(t, `SELECT * FROM t WHERE id IN (SELECT id FROM s WHERE x = ?)`,
+		FingerprintOf(`SELECT * FROM t WHERE id IN ( SELECT id FROM s WHERE x = 1 )`).Text)
+	assert.NotEqual(t, a.Hash, FingerprintOf(`SELECT 1`).Hash)
+
+	e := Composite(`SELECT * FROM t WHERE`, Frag(`id IN (#{ids})`))
+	statement, err := NewContext().WithParams(MapParameters{"ids": []int{1, 2}}).Evaluate(e)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM t WHERE id IN (...)`, statement.Fingerprint().Text)
+	}
+}
@@ -0,0 +1,166 @@
+package sql
+
+import (
+	"strings"
+)
+
+// Format 生成sql的输出格式
+type Format int
+
+const (
+	FormatNone    Format = iota // 保持片段原样，仅以空格拼接
+	FormatCompact               // 压缩字面量之外的空白
+	FormatPretty                // 按关键字换行缩进
+)
+
+// Apply 按格式处理sql
+func (f Format) Apply(stmt string) string {
+	switch f {
+	case FormatCompact:
+		return Compact(stmt)
+	case FormatPretty:
+		return Pretty(stmt)
+	}
+	return stmt
+}
+
+// Compact 将字面量之外的连续空白压缩为一个空格，行注释改写为块注释
+func Compact(stmt string) string {
+	var b strings.Builder
+	spaced := false
+	for _, t := range tokenize(stmt) {
+		if t.kind == tokSpace {
+			spaced = true
+			continue
+		}
+		if spaced && b.Len() != 0 {
+			b.WriteByte(' ')
+		}
+		spaced = false
+		b.WriteString(tokenText(t))
+	}
+	return b.String()
+}
+
+// clauseKeywords 在Pretty格式下另起一行的关键字
+var clauseKeywords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "GROUP": true, "ORDER": true, "HAVING": true,
+	"LIMIT": true, "OFFSET": true, "FETCH": true, "SET": true, "VALUES": true, "UNION": true,
+	"INSERT": true, "UPDATE": true, "DELETE": true, "RETURNING": true,
+	"JOIN": true, "LEFT": true, "RIGHT": true, "INNER": true, "OUTER": true, "FULL": true, "CROSS": true, "NATURAL": true,
+}
+
+// joinModifiers 与后续关键字组成同一子句的关键字，如 LEFT JOIN、DELETE FROM
+var joinModifiers = map[string]bool{
+	"LEFT": true, "RIGHT": true, "INNER": true, "OUTER": true, "FULL": true, "CROSS": true, "NATURAL": true,
+	"DELETE": true, "INSERT": true, "UNION": true, "KEY": true, "DO": true, "FOR": true,
+}
+
+// Pretty 按关键字换行缩进，子查询增加一级缩进，WHERE/ON/HAVING 中的 AND/OR 另起一行
+func Pretty(stmt string) string {
+	p := &prettyPrinter{}
+	for _, t := range tokenize(stmt) {
+		p.write(t)
+	}
+	return p.b.String()
+}
+
+type prettyPrinter struct {
+	b        strings.Builder
+	level    int    // 当前缩进层级
+	parens   []bool // 括号栈，true表示子查询
+	spaced   bool   // 上一个token之后是否有空白
+	between  bool   // 处于 BETWEEN ... AND 中
+	prev     token  // 上一个非空白token
+	subquery bool   // 下一个token若为SELECT，则前一个括号为子查询
+}
+
+func (p *prettyPrinter) write(t token) {
+	if t.kind == tokSpace {
+		p.spaced = true
+		return
+	}
+	defer func() {
+		p.prev = t
+		p.spaced = false
+	}()
+
+	if p.subquery {
+		p.subquery = false
+		if t.is(tokWord, "SELECT") {
+			p.parens[len(p.parens)-1] = true
+			p.level++
+		}
+	}
+
+	u := t.upper()
+	switch {
+	case t.kind == tokPunct && t.text == "(":
+		p.emit(t)
+		p.parens = append(p.parens, false)
+		p.subquery = true
+		return
+	case t.kind == tokPunct && t.text == ")":
+		if len(p.parens) != 0 {
+			isSubquery := p.parens[len(p.parens)-1]
+			p.parens = p.parens[:len(p.parens)-1]
+			if isSubquery {
+				p.level--
+				p.newline(p.level)
+			}
+		}
+		p.emit(t)
+		return
+	case t.kind != tokWord || !p.breakable():
+		p.emit(t)
+		return
+	}
+
+	switch {
+	case u == "BETWEEN":
+		p.between = true
+	case (u == "AND" || u == "OR") && p.between:
+		p.between = false
+	case u == "AND" || u == "OR":
+		p.newline(p.level + 1)
+	case clauseKeywords[u] && !p.continues():
+		p.newline(p.level)
+	}
+	p.emit(t)
+}
+
+// breakable 不在函数调用、IN列表等普通括号内时才换行
+func (p *prettyPrinter) breakable() bool {
+	return len(p.parens) == 0 || p.parens[len(p.parens)-1]
+}
+
+// continues 判断关键字是否与前一个关键字属于同一子句
+func (p *prettyPrinter) continues() bool {
+	if p.prev.kind == tokPunct && p.prev.text != ")" && p.prev.text != "(" {
+		return true
+	}
+	return p.prev.kind == tokWord && joinModifiers[p.prev.upper()]
+}
+
+func (p *prettyPrinter) newline(level int) {
+	if p.b.Len() != 0 {
+		p.b.WriteByte('\n')
+	}
+	p.b.WriteString(strings.Repeat("  ", level))
+	p.spaced = false
+}
+
+func (p *prettyPrinter) emit(t token) {
+	if p.spaced && p.b.Len() != 0 {
+		p.b.WriteByte(' ')
+	}
+	p.b.WriteString(tokenText(t))
+}
+
+// tokenText 输出token，行注释改写为块注释以免吞掉后续语句
+func tokenText(t token) string {
+	if t.kind == tokComment && strings.HasPrefix(t.text, "--") {
+		return "/*" + strings.TrimRight(t.text[2:], "\r") + " */"
+	}
+	return t.text
+}
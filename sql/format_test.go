@@ -0,0 +1,53 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	// compact
+	{
+		stmt := "SELECT  *\n\tFROM gc_image -- images\n WHERE name = 'a  b'\n\t AND id IN (SELECT image_id FROM gc_tag)"
+		assert.Equal(t,
+			"SELECT * FROM gc_image /* images */ WHERE name = 'a  b' AND id IN (SELECT image_id FROM gc_tag)",
+			Compact(stmt))
+	}
+	// pretty
+	{
+		stmt := "SELECT c.id, COUNT(*) FROM gc_creation c LEFT JOIN gc_profile cp ON cp.creation_id = c.id AND cp.stage = ? " +
+			"WHERE c.id IN (SELECT creation_id FROM gc_tag WHERE tag = ?) AND c.ts BETWEEN ? AND ? OR c.featured = 1 " +
+			"GROUP BY c.id ORDER BY c.id DESC LIMIT ?"
+		assert.Equal(t, `SELECT c.id, COUNT(*)
+FROM gc_creation c
+LEFT JOIN gc_profile cp ON cp.creation_id = c.id
+  AND cp.stage = ?
+WHERE c.id IN (
+  SELECT creation_id
+  FROM gc_tag
+  WHERE tag = ?
+)
+  AND c.ts BETWEEN ? AND ?
+  OR c.featured = 1
+GROUP BY c.id
+ORDER BY c.id DESC
+LIMIT ?`, Pretty(stmt))
+	}
+	// context
+	{
+		e := Composite(
+			`SELECT *
+				FROM gc_image`,
+			Where(If(Test(".id"), `id = #{id}`)),
+		)
+		stmt, err := NewContext().
+			WithParams(MapParameters{"id": 1}).
+			WithFormat(FormatCompact).
+			Evaluate(e)
+		if assert.NoError(t, err) {
+			assert.Equal(t, `SELECT * FROM gc_image WHERE id = ?`, stmt.GetStmt())
+			assert.Equal(t, []string{"id"}, stmt.GetArgNames())
+		}
+	}
+}
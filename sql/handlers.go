@@ -0,0 +1,340 @@
+package sql
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSON 以JSON文本存储的列
+func JSON() TypeHandler {
+	return jsonHandler{}
+}
+
+type jsonHandler struct{}
+
+func (jsonHandler) ToDB(value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (jsonHandler) FromDB(src any, dest any) error {
+	b, ok := textOf(src)
+	if !ok {
+		return fmt.Errorf("expect text, got %T", src)
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, dest)
+}
+
+// CSV 以分隔符拼接存储的列表，元素支持字符串、整数、浮点数、布尔类型
+func CSV(sep string) TypeHandler {
+	return csvHandler{sep: sep}
+}
+
+type csvHandler struct {
+	sep string
+}
+
+func (h csvHandler) ToDB(value any) (any, error) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("expect slice, got %T", value)
+	}
+	items := make([]string, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		items = append(items, fmt.Sprint(rv.Index(i).Interface()))
+	}
+	return strings.Join(items, h.sep), nil
+}
+
+func (h csvHandler) FromDB(src any, dest any) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("expect pointer to slice, got %T", dest)
+	}
+	b, ok := textOf(src)
+	if !ok {
+		return fmt.Errorf("expect text, got %T", src)
+	}
+	slice := rv.Elem()
+	var items []string
+	if len(b) != 0 {
+		items = strings.Split(string(b), h.sep)
+	}
+	res := reflect.MakeSlice(slice.Type(), len(items), len(items))
+	for i, item := range items {
+		if err := setText(res.Index(i), item); err != nil {
+			return err
+		}
+	}
+	slice.Set(res)
+	return nil
+}
+
+// UUIDBytes 以16字节二进制存储的UUID，Go中可为标准格式的字符串、[16]byte 或 []byte
+func UUIDBytes() TypeHandler {
+	return uuidHandler{}
+}
+
+type uuidHandler struct{}
+
+func (uuidHandler) ToDB(value any) (any, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		b, err := hex.DecodeString(strings.ReplaceAll(v, "-", ""))
+		if err != nil || len(b) != 16 {
+			return nil, fmt.Errorf("invalid uuid %q", v)
+		}
+		return b, nil
+	case [16]byte:
+		return v[:], nil
+	case []byte:
+		if len(v) != 16 {
+			return nil, fmt.Errorf("invalid uuid length %d", len(v))
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("expect string, [16]byte or []byte, got %T", value)
+}
+
+func (uuidHandler) FromDB(src any, dest any) error {
+	if src == nil {
+		return nil
+	}
+	b, ok := src.([]byte)
+	if !ok || len(b) != 16 {
+		return fmt.Errorf("expect 16 bytes, got %T", src)
+	}
+	switch d := dest.(type) {
+	case *string:
+		s := hex.EncodeToString(b)
+		*d = s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+	case *[16]byte:
+		copy(d[:], b)
+	case *[]byte:
+		*d = slices.Clone(b)
+	default:
+		return fmt.Errorf("expect *string, *[16]byte or *[]byte, got %T", dest)
+	}
+	return nil
+}
+
+// Time 按时区策略转换时间：写入前转换到location，读出的时间及无时区的文本按location解释，location 为nil时使用UTC
+func Time(location *time.Location) TypeHandler {
+	if location == nil {
+		location = time.UTC
+	}
+	return timeHandler{location: location}
+}
+
+type timeHandler struct {
+	location *time.Location
+}
+
+// timeLayouts 解析文本时间时依次尝试的格式
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", "2006-01-02"}
+
+func (h timeHandler) ToDB(value any) (any, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case time.Time:
+		return v.In(h.location), nil
+	case *time.Time:
+		if v == nil {
+			return nil, nil
+		}
+		return v.In(h.location), nil
+	}
+	return nil, fmt.Errorf("expect time.Time, got %T", value)
+}
+
+func (h timeHandler) FromDB(src any, dest any) error {
+	d, ok := dest.(*time.Time)
+	if !ok {
+		return fmt.Errorf("expect *time.Time, got %T", dest)
+	}
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case time.Time:
+		*d = v.In(h.location)
+		return nil
+	}
+	b, ok := textOf(src)
+	if !ok {
+		return fmt.Errorf("expect time or text, got %T", src)
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, string(b), h.location); err == nil {
+			*d = t.In(h.location)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid time %q", b)
+}
+
+// Enum 以字符串或整数为底层类型的枚举，写入及读出时校验取值
+func Enum[T comparable](values ...T) TypeHandler {
+	return enumHandler[T]{values: values}
+}
+
+type enumHandler[T comparable] struct {
+	values []T
+}
+
+func (h enumHandler[T]) ToDB(value any) (any, error) {
+	v, ok := value.(T)
+	if !ok {
+		return nil, fmt.Errorf("expect %T, got %T", *new(T), value)
+	}
+	if !slices.Contains(h.values, v) {
+		return nil, fmt.Errorf("invalid enum value %v", v)
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), nil
+	}
+	return nil, fmt.Errorf("enum %T must be string or integer based", v)
+}
+
+func (h enumHandler[T]) FromDB(src any, dest any) error {
+	d, ok := dest.(*T)
+	if !ok {
+		return fmt.Errorf("expect *%T, got %T", *new(T), dest)
+	}
+	if src == nil {
+		return nil
+	}
+	var v T
+	rv := reflect.ValueOf(&v).Elem()
+	if b, ok := textOf(src); ok {
+		if err := setText(rv, string(b)); err != nil {
+			return err
+		}
+	} else if n, ok := toInt64(src); ok && rv.CanInt() {
+		rv.SetInt(n)
+	} else {
+		return fmt.Errorf("cannot convert %T to %T", src, v)
+	}
+	if !slices.Contains(h.values, v) {
+		return fmt.Errorf("invalid enum value %v", v)
+	}
+	*d = v
+	return nil
+}
+
+// textOf 驱动返回的文本列值
+func textOf(src any) ([]byte, bool) {
+	switch v := src.(type) {
+	case nil:
+		return nil, true
+	case []byte:
+		return v, true
+	case string:
+		return []byte(v), true
+	}
+	return nil, false
+}
+
+// setText 将文本按目标类型解析后赋值
+func setText(dst reflect.Value, s string) error {
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+		if err != nil {
+			return err
+		}
+		dst.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+		if err != nil {
+			return err
+		}
+		dst.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return err
+		}
+		dst.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(s))
+		if err != nil {
+			return err
+		}
+		dst.SetBool(b)
+	default:
+		return fmt.Errorf("cannot parse text into %s", dst.Type())
+	}
+	return nil
+}
+
+// UnixMillis 以毫秒时间戳存储的时间
+func UnixMillis() TypeHandler {
+	return unixMillisHandler{}
+}
+
+type unixMillisHandler struct{}
+
+func (unixMillisHandler) ToDB(value any) (any, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case time.Time:
+		return v.UnixMilli(), nil
+	case *time.Time:
+		if v == nil {
+			return nil, nil
+		}
+		return v.UnixMilli(), nil
+	}
+	return nil, fmt.Errorf("expect time.Time, got %T", value)
+}
+
+func (unixMillisHandler) FromDB(src any, dest any) error {
+	d, ok := dest.(*time.Time)
+	if !ok {
+		return fmt.Errorf("expect *time.Time, got %T", dest)
+	}
+	if src == nil {
+		return nil
+	}
+	ms, ok := toInt64(src)
+	if !ok {
+		b, text := textOf(src)
+		if !text {
+			return fmt.Errorf("expect integer, got %T", src)
+		}
+		n, err := strconv.ParseInt(string(b), 10, 64)
+		if err != nil {
+			return err
+		}
+		ms = n
+	}
+	*d = time.UnixMilli(ms)
+	return nil
+}
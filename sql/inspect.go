@@ -0,0 +1,287 @@
+package sql
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/non1996/go-batis/errors"
+)
+
+// ParameterSet 一个sql定义静态可引用的全部参数，按守护它们的条件分组
+type ParameterSet struct {
+	ID       string            `json:"id"`
+	Groups   []*ParameterGroup `json:"groups"`
+	Includes []*IncludeProps   `json:"includes,omitempty"`
+}
+
+// ParameterGroup 受同一组条件守护的参数，Conditions 为空表示无条件引用
+type ParameterGroup struct {
+	Conditions []string `json:"conditions,omitempty"`
+	Params     []string `json:"params,omitempty"` // #{} 参数
+	Props      []string `json:"props,omitempty"`  // ${} 属性
+	Tested     []string `json:"tested,omitempty"` // 条件中读取的参数
+}
+
+// IncludeProps 一个 include 引用及其传递给被引用片段的属性
+type IncludeProps struct {
+	From       string            `json:"from"` // include 所在的sql id
+	ID         string            `json:"id"`
+	IDFromProp bool              `json:"idFromProp,omitempty"`
+	Conditions []string          `json:"conditions,omitempty"`
+	Props      map[string]string `json:"props,omitempty"` // 以$开头的值取自调用方参数
+}
+
+// Params 返回所有分组中的 #{} 参数，去重并保持出现顺序
+func (ps *ParameterSet) Params() []string {
+	return ps.collect(func(g *ParameterGroup) []string { return g.Params })
+}
+
+// Props 返回所有分组中的 ${} 属性，去重并保持出现顺序
+func (ps *ParameterSet) Props() []string {
+	return ps.collect(func(g *ParameterGroup) []string { return g.Props })
+}
+
+// Tested 返回所有条件中读取的参数，去重并保持出现顺序
+func (ps *ParameterSet) Tested() []string {
+	return ps.collect(func(g *ParameterGroup) []string { return g.Tested })
+}
+
+func (ps *ParameterSet) collect(get func(*ParameterGroup) []string) []string {
+	var res []string
+	for _, g := range ps.Groups {
+		for _, name := range get(g) {
+			if !slices.Contains(res, name) {
+				res = append(res, name)
+			}
+		}
+	}
+	return res
+}
+
+// Parameters 静态分析sql定义（包括其引用的片段）可引用的参数，
+// 被引用片段中由 include 属性提供的参数不计入调用方需要提供的参数
+func (c Collection) Parameters(id string) (*ParameterSet, error) {
+	return parameters(c, id)
+}
+
+func parameters(defs Definitions, id string) (*ParameterSet, error) {
+	sql, exist := defs.Lookup(id)
+	if !exist {
+		return nil, errors.MissingSQL(id)
+	}
+	w := &walker{
+		collection: defs,
+		set:        &ParameterSet{ID: id},
+		groups:     map[string]*ParameterGroup{},
+		path:       []string{id},
+	}
+	if err := w.walk(sql); err != nil {
+		return nil, err
+	}
+	return w.set, nil
+}
+
+// inspectable 支持静态分析的元素
+type inspectable interface {
+	inspect(w *walker) error
+}
+
+// walker 静态遍历sql元素，记录当前所处的条件及 include 提供的属性
+type walker struct {
+	collection Definitions
+	set        *ParameterSet
+	groups     map[string]*ParameterGroup
+	conditions []string
+	supplied   []Parameters // 外层 include 提供的属性，内层在后
+	path       []string     // 当前 include 链，用于检测循环引用
+}
+
+func (w *walker) walk(e Elem) error {
+	if i, ok := e.(inspectable); ok {
+		return i.inspect(w)
+	}
+	return nil
+}
+
+func (w *walker) walkAll(elems []Elem) error {
+	for _, e := range elems {
+		if err := w.walk(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// guarded 在附加条件下执行fn
+func (w *walker) guarded(conditions []string, fn func() error) error {
+	n := len(w.conditions)
+	w.conditions = append(w.conditions, conditions...)
+	defer func() { w.conditions = w.conditions[:n] }()
+	return fn()
+}
+
+func (w *walker) group() *ParameterGroup {
+	key := strings.Join(w.conditions, "\x00")
+	g, exist := w.groups[key]
+	if !exist {
+		g = &ParameterGroup{Conditions: slices.Clone(w.conditions)}
+		w.groups[key] = g
+		w.set.Groups = append(w.set.Groups, g)
+	}
+	return g
+}
+
+// isSupplied 判断参数是否由外层 include 的属性提供
+func (w *walker) isSupplied(name string) bool {
+	for _, props := range w.supplied {
+		if props.Exist(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *walker) param(name string) {
+	if w.isSupplied(name) {
+		return
+	}
+	g := w.group()
+	if !slices.Contains(g.Params, name) {
+		g.Params = append(g.Params, name)
+	}
+}
+
+func (w *walker) prop(name string) {
+	if w.isSupplied(name) || w.isProperty(name) {
+		return
+	}
+	g := w.group()
+	if !slices.Contains(g.Props, name) {
+		g.Props = append(g.Props, name)
+	}
+}
+
+// isProperty 属性已由sql定义来源上的全局配置满足，调用方无需传入
+func (w *walker) isProperty(name string) bool {
+	source, ok := w.collection.(propertySource)
+	if !ok {
+		return false
+	}
+	_, exist := source.Properties().Get(name)
+	return exist
+}
+
+func (w *walker) test(c Condition) {
+	t, ok := c.(*templateCondition)
+	if !ok {
+		return
+	}
+	for _, name := range t.fields {
+		if w.isSupplied(name) {
+			continue
+		}
+		g := w.group()
+		if !slices.Contains(g.Tested, name) {
+			g.Tested = append(g.Tested, name)
+		}
+	}
+}
+
+// guards 条件对应的守护文本，恒真条件不计入
+func guards(c Condition) []string {
+	if c == tc {
+		return nil
+	}
+	return []string{conditionString(c)}
+}
+
+func (s *pure) inspect(w *walker) error {
+	return nil
+}
+
+func (s *fragment) inspect(w *walker) error {
+	for _, prop := range s.properties {
+		w.prop(prop)
+	}
+	for _, param := range s.parameters {
+		w.param(ParseParam(param).Name)
+	}
+	return nil
+}
+
+func (s *_include) inspect(w *walker) error {
+	include := &IncludeProps{
+		From:       w.path[len(w.path)-1],
+		ID:         s.ID,
+		IDFromProp: s.IDFromProp,
+		Conditions: slices.Clone(w.conditions),
+		Props:      map[string]string{},
+	}
+	for _, k := range s.Props.Keys() {
+		vs := String(s.Props.Get(k))
+		include.Props[k] = vs
+		if vs[0] == '$' {
+			w.prop(vs[1:])
+		}
+	}
+	w.set.Includes = append(w.set.Includes, include)
+
+	if s.IDFromProp {
+		w.prop(s.ID)
+		return nil
+	}
+
+	current := w.path[len(w.path)-1]
+	id, refed, exist := resolveID(w.collection, namespaceOf(current), s.ID)
+	if !exist {
+		return errors.MissingSQL(s.ID)
+	}
+	if slices.Contains(w.path, id) {
+		return errors.CyclicInclude(append(slices.Clone(w.path), id))
+	}
+
+	w.path = append(w.path, id)
+	w.supplied = append(w.supplied, s.Props)
+	defer func() {
+		w.path = w.path[:len(w.path)-1]
+		w.supplied = w.supplied[:len(w.supplied)-1]
+	}()
+	return w.walk(refed)
+}
+
+func (s *_if) inspect(w *walker) error {
+	w.test(s.Condition)
+	return w.guarded(guards(s.Condition), func() error {
+		return w.walkAll(s.Children)
+	})
+}
+
+func (s *choose) inspect(w *walker) error {
+	var negated []string
+	for _, child := range s.Children {
+		if err := w.guarded(negated, func() error {
+			return w.walk(child)
+		}); err != nil {
+			return err
+		}
+		if c, ok := child.(*_if); ok {
+			for _, g := range guards(c.Condition) {
+				negated = append(negated, "not ("+g+")")
+			}
+		}
+	}
+	return nil
+}
+
+func (s *trim) inspect(w *walker) error {
+	for _, child := range s.Children {
+		if err := w.walk(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *composite) inspect(w *walker) error {
+	return w.walkAll(s.Children)
+}
@@ -0,0 +1,56 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParameters(t *testing.T) {
+	collection := Collection{
+		"Image.SelectFields": testCollection["Image.SelectFields"],
+		"Image.Search": Composite(
+			`SELECT`,
+			Include("Image.SelectFields", false, MapParameters{
+				"alias":  "gi",
+				"digest": "$digestColumn",
+			}),
+			`FROM ${table} gi`,
+			Where(
+				If(Test(".state"), `gi.state = #{state}`),
+				Choose(
+					When(Test(".title"), `AND gi.title LIKE #{title}`),
+					OtherWise(`AND gi.featured = #{featured}`),
+				),
+			),
+		),
+		"Loop": Include("Loop", false, nil),
+	}
+
+	set, err := collection.Parameters("Image.Search")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []string{"state", "title", "featured"}, set.Params())
+	assert.Equal(t, []string{"digestColumn", "table"}, set.Props())
+	assert.Equal(t, []string{"state", "title"}, set.Tested())
+
+	if assert.Len(t, set.Groups, 4) {
+		assert.Empty(t, set.Groups[0].Conditions)
+		assert.Equal(t, []string{".state"}, set.Groups[1].Conditions)
+		assert.Equal(t, []string{".title"}, set.Groups[2].Conditions)
+		assert.Equal(t, []string{"not (.title)"}, set.Groups[3].Conditions)
+		assert.Equal(t, []string{"featured"}, set.Groups[3].Params)
+	}
+	if assert.Len(t, set.Includes, 1) {
+		assert.Equal(t, "Image.SelectFields", set.Includes[0].ID)
+		assert.Equal(t, map[string]string{"alias": "gi", "digest": "$digestColumn"}, set.Includes[0].Props)
+	}
+
+	_, err = collection.Parameters("Loop")
+	assert.EqualError(t, err, "cyclic include: Loop -> Loop")
+
+	_, err = collection.Parameters("Missing")
+	assert.EqualError(t, err, "missing sql: Missing")
+}
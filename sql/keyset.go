@@ -0,0 +1,349 @@
+package sql
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/non1996/go-batis/errors"
+)
+
+// SortKey keyset分页的排序键，Field 为结果中对应的字段名，为空时取 Column 去掉表别名后的部分
+type SortKey struct {
+	Column string
+	Field  string
+	Desc   bool
+}
+
+func Asc(column string) SortKey {
+	return SortKey{Column: column}
+}
+
+func Desc(column string) SortKey {
+	return SortKey{Column: column, Desc: true}
+}
+
+// As 指定结果中对应的字段名
+func (k SortKey) As(field string) SortKey {
+	k.Field = field
+	return k
+}
+
+// FieldName 结果中对应的字段名
+func (k SortKey) FieldName() string {
+	if k.Field != "" {
+		return k.Field
+	}
+	if idx := strings.LastIndex(k.Column, "."); idx >= 0 {
+		return k.Column[idx+1:]
+	}
+	return k.Column
+}
+
+// Keyset keyset（seek）分页，按排序键从游标位置之后读取，游标由上一页最后一行的排序键值编码而成
+//
+//	keyset := sql.Seek("cursor", "size", sql.Desc("created_at"), sql.Asc("id"))
+//	sql.Composite(`SELECT * FROM t`, sql.Where(..., keyset.Where()), keyset.OrderBy(), keyset.Limit())
+type Keyset struct {
+	CursorParam string // 游标参数名，值为空字符串或 nil 时读取第一页
+	LimitParam  string // 每页条数参数名
+	Keys        []SortKey
+}
+
+func Seek(cursorParam, limitParam string, keys ...SortKey) *Keyset {
+	return &Keyset{
+		CursorParam: cursorParam,
+		LimitParam:  limitParam,
+		Keys:        keys,
+	}
+}
+
+// Where 游标之后的条件，以 AND 开头，在 Where 中使用；没有游标时不输出
+func (k *Keyset) Where() ConditionElem {
+	return &seekPredicate{keyset: k}
+}
+
+// OrderBy 按排序键排序
+func (k *Keyset) OrderBy() Elem {
+	return &seekOrder{keyset: k}
+}
+
+// Limit 按方言输出每页条数
+func (k *Keyset) Limit() Elem {
+	return &seekLimit{keyset: k}
+}
+
+// EncodeCursor 将排序键值编码为游标，time.Time 单独标记，解码后仍为 time.Time 而不是字符串
+func EncodeCursor(values ...any) (string, error) {
+	encoded := make([]any, len(values))
+	for i, v := range values {
+		switch t := v.(type) {
+		case time.Time:
+			encoded[i] = cursorTime{Time: t.Format(time.RFC3339Nano)}
+		case *time.Time:
+			if t != nil {
+				encoded[i] = cursorTime{Time: t.Format(time.RFC3339Nano)}
+			}
+		default:
+			encoded[i] = v
+		}
+	}
+	b, err := json.Marshal(encoded)
+	if err != nil {
+		return "", errors.InvalidCursor(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// DecodeCursor 解码游标，整数解码为 int64，其他数字解码为 float64，时间解码为 time.Time
+func DecodeCursor(cursor string) ([]any, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.InvalidCursor(err)
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(b)))
+	decoder.UseNumber()
+	var values []any
+	if err = decoder.Decode(&values); err != nil {
+		return nil, errors.InvalidCursor(err)
+	}
+	for i, v := range values {
+		switch value := v.(type) {
+		case json.Number:
+			if values[i], err = value.Int64(); err != nil {
+				values[i], _ = value.Float64()
+			}
+		case map[string]any:
+			s, ok := value["$time"].(string)
+			if !ok || len(value) != 1 {
+				return nil, errors.InvalidCursor(fmt.Errorf("unexpected object at %d", i))
+			}
+			if values[i], err = time.Parse(time.RFC3339Nano, s); err != nil {
+				return nil, errors.InvalidCursor(err)
+			}
+		}
+	}
+	return values, nil
+}
+
+// cursorTime 游标中的时间值
+type cursorTime struct {
+	Time string `json:"$time"`
+}
+
+// cursor 游标参数的值
+func (k *Keyset) cursor(ctx *Context) (string, error) {
+	value, exist := ctx.Lookup(k.CursorParam)
+	if !exist {
+		return "", errors.MissingParameter(k.CursorParam)
+	}
+	switch cursor := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return cursor, nil
+	case *string:
+		if cursor == nil {
+			return "", nil
+		}
+		return *cursor, nil
+	}
+	return "", errors.InvalidParameter(k.CursorParam, fmt.Sprintf("expect string, got %T", value))
+}
+
+// sameDirection 所有排序键方向相同
+func (k *Keyset) sameDirection() bool {
+	for _, key := range k.Keys {
+		if key.Desc != k.Keys[0].Desc {
+			return false
+		}
+	}
+	return true
+}
+
+func comparator(desc bool) string {
+	if desc {
+		return " < "
+	}
+	return " > "
+}
+
+// seekPredicate 游标之后的条件。排序方向相同且方言支持行值比较时输出 (a, b) > (?, ?)，
+// 否则输出 (a > ? OR (a = ? AND b > ?))
+type seekPredicate struct {
+	keyset *Keyset
+}
+
+func (s *seekPredicate) Satisfy(ctx *Context) (bool, error) {
+	cursor, err := s.keyset.cursor(ctx)
+	return cursor != "", err
+}
+
+func (s *seekPredicate) String() string {
+	return s.keyset.CursorParam + " != \"\""
+}
+
+func (s *seekPredicate) Evaluate(ctx *Context) (statement *Statement, err error) {
+	node, err := ctx.enter(s)
+	defer func() { ctx.leave(node, statement, err) }()
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := s.keyset.cursor(ctx)
+	if err != nil || cursor == "" {
+		return emptyStatement, err
+	}
+	values, err := DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	keys := s.keyset.Keys
+	if len(values) != len(keys) {
+		return nil, errors.InvalidCursor(fmt.Errorf("expect %d values, got %d", len(keys), len(values)))
+	}
+
+	statement = &Statement{}
+	names := make([]string, 0, len(values))
+	for _, v := range values {
+		names = append(names, ctx.bind(statement, v))
+	}
+
+	if s.keyset.sameDirection() && rowValues(ctx.dialect) {
+		columns := make([]string, 0, len(keys))
+		for _, key := range keys {
+			columns = append(columns, key.Column)
+		}
+		statement.Stmt = "AND (" + strings.Join(columns, ", ") + ")" + comparator(keys[0].Desc) +
+			"(" + strings.TrimSuffix(strings.Repeat("?, ", len(keys)), ", ") + ")"
+		return statement, nil
+	}
+
+	// 展开为 a > ? OR (a = ? AND b > ?) ...，参数按出现顺序排列
+	statement.ArgNames = nil
+	var branches []string
+	for i, key := range keys {
+		var conds []string
+		for j := 0; j < i; j++ {
+			conds = append(conds, keys[j].Column+" = ?")
+			statement.ArgNames = append(statement.ArgNames, names[j])
+		}
+		conds = append(conds, key.Column+comparator(key.Desc)+"?")
+		statement.ArgNames = append(statement.ArgNames, names[i])
+		if len(conds) == 1 {
+			branches = append(branches, conds[0])
+		} else {
+			branches = append(branches, "("+strings.Join(conds, " AND ")+")")
+		}
+	}
+	statement.Stmt = "AND (" + strings.Join(branches, " OR ") + ")"
+	return statement, nil
+}
+
+// rowValues 支持行值比较的方言
+func rowValues(dialect Dialect) bool {
+	return dialect == MySQL || dialect == PostgreSQL || dialect == SQLite
+}
+
+// seekOrder 按排序键排序，求值计数语句时不输出
+type seekOrder struct {
+	keyset *Keyset
+}
+
+func (s *seekOrder) Evaluate(ctx *Context) (statement *Statement, err error) {
+	node, err := ctx.enter(s)
+	defer func() { ctx.leave(node, statement, err) }()
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.counting {
+		return emptyStatement, nil
+	}
+	orders := make([]string, 0, len(s.keyset.Keys))
+	for _, key := range s.keyset.Keys {
+		if key.Desc {
+			orders = append(orders, key.Column+" DESC")
+		} else {
+			orders = append(orders, key.Column+" ASC")
+		}
+	}
+	return NewStatement("ORDER BY "+strings.Join(orders, ", "), nil), nil
+}
+
+// seekLimit 按方言输出每页条数，并记录为分页参数的 Size，求值计数语句时不输出
+type seekLimit struct {
+	keyset *Keyset
+}
+
+func (s *seekLimit) Evaluate(ctx *Context) (statement *Statement, err error) {
+	node, err := ctx.enter(s)
+	defer func() { ctx.leave(node, statement, err) }()
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.counting {
+		return emptyStatement, nil
+	}
+	value, exist := ctx.Lookup(s.keyset.LimitParam)
+	if !exist {
+		return nil, errors.MissingParameter(s.keyset.LimitParam)
+	}
+	if limit, ok := toInt64(value); ok {
+		ctx.recordPage(PageRequest{Size: limit})
+	}
+
+	mark := "?"
+	if ctx.named {
+		mark = ":" + s.keyset.LimitParam
+	}
+	switch ctx.dialect {
+	case SQLServer, Oracle:
+		return NewStatement("OFFSET 0 ROWS FETCH NEXT "+mark+" ROWS ONLY", []string{s.keyset.LimitParam}), nil
+	}
+	return NewStatement("LIMIT "+mark, []string{s.keyset.LimitParam}), nil
+}
+
+func (s *seekPredicate) inspect(w *walker) error {
+	w.param(s.keyset.CursorParam)
+	return nil
+}
+
+func (s *seekOrder) inspect(w *walker) error {
+	return nil
+}
+
+func (s *seekLimit) inspect(w *walker) error {
+	w.param(s.keyset.LimitParam)
+	return nil
+}
+
+func (k *Keyset) goSource() string {
+	keys := make([]string, 0, len(k.Keys))
+	for _, key := range k.Keys {
+		src := fmt.Sprintf("sql.Asc(%q)", key.Column)
+		if key.Desc {
+			src = fmt.Sprintf("sql.Desc(%q)", key.Column)
+		}
+		if key.Field != "" {
+			src += fmt.Sprintf(".As(%q)", key.Field)
+		}
+		keys = append(keys, src)
+	}
+	return fmt.Sprintf("sql.Seek(%q, %q, %s)", k.CursorParam, k.LimitParam, strings.Join(keys, ", "))
+}
+
+func (s *seekPredicate) goSource() (string, error) {
+	return s.keyset.goSource() + ".Where()", nil
+}
+
+func (s *seekOrder) goSource() (string, error) {
+	return s.keyset.goSource() + ".OrderBy()", nil
+}
+
+func (s *seekLimit) goSource() (string, error) {
+	return s.keyset.goSource() + ".Limit()", nil
+}
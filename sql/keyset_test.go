@@ -0,0 +1,76 @@
+package sql
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyset(t *testing.T) {
+	cursor, err := EncodeCursor("2024-01-01", 100)
+	if !assert.NoError(t, err) {
+		return
+	}
+	values, err := DecodeCursor(cursor)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []any{"2024-01-01", int64(100)}, values)
+	}
+
+	// 时间解码后仍为 time.Time，按时间类型绑定参数
+	createdAt := time.Date(2024, 1, 1, 8, 30, 0, 123000000, time.FixedZone("CST", 8*3600))
+	timeCursor, err := EncodeCursor(createdAt, 100)
+	if assert.NoError(t, err) {
+		values, err = DecodeCursor(timeCursor)
+		if assert.NoError(t, err) && assert.Len(t, values, 2) {
+			assert.True(t, createdAt.Equal(values[0].(time.Time)))
+			assert.Equal(t, int64(100), values[1])
+		}
+	}
+	_, err = DecodeCursor(base64.RawURLEncoding.EncodeToString([]byte(`[{"$time":"yesterday"}]`)))
+	assert.Error(t, err)
+
+	render := func(keyset *Keyset, dialect Dialect, cursor any) (string, []any) {
+		query := Composite(`SELECT * FROM gc_image gi`, Where(If(Test(".md5"), `AND gi.md5 = #{md5}`), keyset.Where()), keyset.OrderBy(), keyset.Limit())
+		params := MapParameters{"md5": "abc", "cursor": cursor, "size": 20}
+		stmt, args, err := Prepare(NewContext().WithParams(params).WithDialect(dialect), query).Prepare()
+		assert.NoError(t, err)
+		return stmt, args
+	}
+
+	// 方向相同时使用行值比较
+	asc := Seek("cursor", "size", Asc("gi.created_at"), Asc("gi.id"))
+	stmt, args := render(asc, MySQL, cursor)
+	assert.Equal(t, `SELECT * FROM gc_image gi WHERE gi.md5 = ? AND (gi.created_at, gi.id) > (?, ?) ORDER BY gi.created_at ASC, gi.id ASC LIMIT ?`, stmt)
+	assert.Equal(t, []any{"abc", "2024-01-01", int64(100), 20}, args)
+
+	// 第一页没有游标条件
+	stmt, args = render(asc, MySQL, "")
+	assert.Equal(t, `SELECT * FROM gc_image gi WHERE gi.md5 = ? ORDER BY gi.created_at ASC, gi.id ASC LIMIT ?`, stmt)
+	assert.Equal(t, []any{"abc", 20}, args)
+
+	// 方向不同或方言不支持行值比较时展开
+	mixed := Seek("cursor", "size", Desc("gi.created_at"), Asc("gi.id"))
+	stmt, args = render(mixed, PostgreSQL, cursor)
+	assert.Equal(t, `SELECT * FROM gc_image gi WHERE gi.md5 = $1 AND (gi.created_at < $2 OR (gi.created_at = $3 AND gi.id > $4)) ORDER BY gi.created_at DESC, gi.id ASC LIMIT $5`, stmt)
+	assert.Equal(t, []any{"abc", "2024-01-01", "2024-01-01", int64(100), 20}, args)
+
+	stmt, _ = render(asc, SQLServer, cursor)
+	assert.Equal(t, `SELECT * FROM gc_image gi WHERE gi.md5 = @p1 AND (gi.created_at > @p2 OR (gi.created_at = @p3 AND gi.id > @p4)) ORDER BY gi.created_at ASC, gi.id ASC OFFSET 0 ROWS FETCH NEXT @p5 ROWS ONLY`, stmt)
+
+	_, args = render(asc, MySQL, timeCursor)
+	if assert.Len(t, args, 4) {
+		assert.IsType(t, time.Time{}, args[1])
+	}
+
+	_, _, err = Prepare(NewContext().WithParams(MapParameters{"cursor": "!!", "size": 20}), asc.Where()).Prepare()
+	assert.Error(t, err)
+
+	assert.Equal(t, "id", Asc("gi.id").FieldName())
+	assert.Equal(t, "createdAt", Asc("gi.created_at").As("createdAt").FieldName())
+	src, err := GoSource(mixed.OrderBy())
+	if assert.NoError(t, err) {
+		assert.Equal(t, `sql.Seek("cursor", "size", sql.Desc("gi.created_at"), sql.Asc("gi.id")).OrderBy()`, src)
+	}
+}
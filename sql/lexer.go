@@ -0,0 +1,122 @@
+package sql
+
+import (
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokSpace       tokenKind = iota // 空白
+	tokWord                         // 关键字、标识符
+	tokNumber                       // 数字字面量
+	tokString                       // 字符串字面量 '...'
+	tokQuoted                       // 带引号的标识符 `...` "..."
+	tokPlaceholder                  // 占位符 ? $1 :name @p1
+	tokComment                      // 注释 -- /* */
+	tokPunct                        // 标点及运算符
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// upper 关键字比较用的大写形式
+func (t token) upper() string {
+	return strings.ToUpper(t.text)
+}
+
+func (t token) is(kind tokenKind, text string) bool {
+	return t.kind == kind && strings.EqualFold(t.text, text)
+}
+
+// tokenize 对sql做词法切分，能正确识别字面量和注释，不做语法分析
+func tokenize(stmt string) []token {
+	var tokens []token
+	for i := 0; i < len(stmt); {
+		kind, n := scanToken(stmt[i:])
+		tokens = append(tokens, token{kind: kind, text: stmt[i : i+n]})
+		i += n
+	}
+	return tokens
+}
+
+func scanToken(s string) (tokenKind, int) {
+	c := s[0]
+	switch {
+	case isSpace(c):
+		return tokSpace, scanWhile(s, isSpace)
+	case c == '\'':
+		return tokString, scanQuoted(s, '\'')
+	case c == '"' || c == '`':
+		return tokQuoted, scanQuoted(s, c)
+	case c == '-' && strings.HasPrefix(s, "--"):
+		if end := strings.IndexByte(s, '\n'); end >= 0 {
+			return tokComment, end
+		}
+		return tokComment, len(s)
+	case c == '/' && strings.HasPrefix(s, "/*"):
+		if end := strings.Index(s[2:], "*/"); end >= 0 {
+			return tokComment, end + 4
+		}
+		return tokComment, len(s)
+	case c == '?' && strings.HasPrefix(s, "??"):
+		return tokPunct, 2 // 转义的 ? 运算符，如 PostgreSQL jsonb 的 ?、?|、?&
+	case c == '?':
+		return tokPlaceholder, 1
+	case (c == '$' || c == '@') && len(s) > 1 && isWord(s[1]):
+		return tokPlaceholder, 1 + scanWhile(s[1:], isWord)
+	case c == ':' && len(s) > 1 && isWord(s[1]):
+		return tokPlaceholder, 1 + scanWhile(s[1:], isWord)
+	case c == ':' && strings.HasPrefix(s, "::"):
+		return tokPunct, 2
+	case isDigit(c):
+		return tokNumber, scanWhile(s, func(c byte) bool { return isDigit(c) || c == '.' })
+	case isWord(c):
+		return tokWord, scanWhile(s, isWord)
+	}
+	for _, op := range []string{"<=", ">=", "<>", "!=", "||"} {
+		if strings.HasPrefix(s, op) {
+			return tokPunct, len(op)
+		}
+	}
+	return tokPunct, 1
+}
+
+// scanQuoted 扫描引号包裹的内容，支持重复引号及反斜杠转义
+func scanQuoted(s string, quote byte) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+func scanWhile(s string, pred func(byte) bool) int {
+	i := 0
+	for i < len(s) && pred(s[i]) {
+		i++
+	}
+	return i
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isWord(c byte) bool {
+	return c == '_' || c >= 0x80 || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
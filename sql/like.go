@@ -0,0 +1,112 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+)
+
+type likeMode int
+
+const (
+	likeContains likeMode = iota
+	likePrefix
+	likeSuffix
+)
+
+// Contains column LIKE '%值%'，参数值中的通配符会被转义，参数为空时不输出，以 AND 开头，在 Where 中使用
+func Contains(column, param string) ConditionElem {
+	return &like{Column: column, Param: param, Mode: likeContains}
+}
+
+// StartsWith column LIKE '值%'，见 Contains
+func StartsWith(column, param string) ConditionElem {
+	return &like{Column: column, Param: param, Mode: likePrefix}
+}
+
+// EndsWith column LIKE '%值'，见 Contains
+func EndsWith(column, param string) ConditionElem {
+	return &like{Column: column, Param: param, Mode: likeSuffix}
+}
+
+// EscapeLike 按方言转义 LIKE 中的通配符，转义字符为 !，
+// 不使用反斜杠是因为各方言对字符串中反斜杠的处理不一致
+func EscapeLike(s string, dialect Dialect) string {
+	specials := `!%_`
+	if dialect == SQLServer {
+		specials += "["
+	}
+	var b strings.Builder
+	for _, c := range s {
+		if strings.ContainsRune(specials, c) {
+			b.WriteByte('!')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// like 匹配用户输入的 LIKE 条件，转义后的模式作为推导参数绑定，不修改调用方的参数
+type like struct {
+	Column string
+	Param  string
+	Mode   likeMode
+}
+
+func (s *like) value(ctx *Context) string {
+	value, exist := ctx.Lookup(s.Param)
+	if !exist || value == nil {
+		return ""
+	}
+	if p, ok := value.(*string); ok {
+		if p == nil {
+			return ""
+		}
+		return *p
+	}
+	return String(value)
+}
+
+func (s *like) Satisfy(ctx *Context) (bool, error) {
+	return s.value(ctx) != "", nil
+}
+
+func (s *like) String() string {
+	return s.Param + ` != ""`
+}
+
+func (s *like) Evaluate(ctx *Context) (statement *Statement, err error) {
+	node, err := ctx.enter(s)
+	defer func() { ctx.leave(node, statement, err) }()
+	if err != nil {
+		return nil, err
+	}
+
+	value := s.value(ctx)
+	if value == "" {
+		return emptyStatement, nil
+	}
+	pattern := EscapeLike(value, ctx.dialect)
+	switch s.Mode {
+	case likeContains:
+		pattern = "%" + pattern + "%"
+	case likePrefix:
+		pattern = pattern + "%"
+	case likeSuffix:
+		pattern = "%" + pattern
+	}
+
+	statement = &Statement{}
+	ctx.bind(statement, pattern)
+	statement.Stmt = "AND " + s.Column + " LIKE ? ESCAPE '!'"
+	return statement, nil
+}
+
+func (s *like) inspect(w *walker) error {
+	w.param(s.Param)
+	return nil
+}
+
+func (s *like) goSource() (string, error) {
+	fn := map[likeMode]string{likeContains: "Contains", likePrefix: "StartsWith", likeSuffix: "EndsWith"}[s.Mode]
+	return fmt.Sprintf("sql.%s(%q, %q)", fn, s.Column, s.Param), nil
+}
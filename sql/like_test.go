@@ -0,0 +1,38 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLike(t *testing.T) {
+	e := Composite(`SELECT * FROM gc_image`, Where(If(Test(".md5"), `AND md5 = #{md5}`), Contains("title", "title"), StartsWith("path", "path")))
+	params := MapParameters{"md5": "abc", "title": `50%_off!`, "path": "a[1]"}
+
+	stmt, args, err := Prepare(NewContext().WithParams(params).WithDialect(MySQL), e).Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM gc_image WHERE md5 = ? AND title LIKE ? ESCAPE '!' AND path LIKE ? ESCAPE '!'`, stmt)
+		assert.Equal(t, []any{"abc", `%50!%!_off!!%`, "a[1]%"}, args)
+	}
+	// 不修改调用方的参数
+	assert.Equal(t, `50%_off!`, params["title"])
+
+	stmt, args, err = Prepare(NewContext().WithParams(params).WithDialect(SQLServer), e).Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM gc_image WHERE md5 = @p1 AND title LIKE @p2 ESCAPE '!' AND path LIKE @p3 ESCAPE '!'`, stmt)
+		assert.Equal(t, `a![1]%`, args[2])
+	}
+
+	// 参数为空时不输出
+	stmt, _, err = Prepare(NewContext().WithParams(MapParameters{"md5": "", "title": "cat"}), e).Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM gc_image WHERE title LIKE ? ESCAPE '!'`, stmt)
+	}
+
+	stmt, args, err = Prepare(NewContext().WithParams(MapParameters{"name": "cat"}), EndsWith("name", "name")).Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `AND name LIKE ? ESCAPE '!'`, stmt)
+		assert.Equal(t, []any{"%cat"}, args)
+	}
+}
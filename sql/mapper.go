@@ -0,0 +1,451 @@
+package sql
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/non1996/go-batis/errors"
+)
+
+// StatementKind mapper文件中sql定义的类型
+type StatementKind string
+
+const (
+	KindSQL    StatementKind = "sql"
+	KindSelect StatementKind = "select"
+	KindInsert StatementKind = "insert"
+	KindUpdate StatementKind = "update"
+	KindDelete StatementKind = "delete"
+)
+
+// Mapper 一个mapper文件，格式与mybatis的xml mapper一致，条件使用go template语法
+//
+//	<mapper namespace="Image">
+//	  <sql id="Fields">${alias}.id, ${alias}.md5</sql>
+//	  <select id="Search" resultType="Image">
+//	    SELECT <include refid="Fields"><property name="alias" value="gi"/></include>
+//	    FROM gc_image gi
+//	    <where><if test=".md5">gi.md5 = #{md5}</if></where>
+//	  </select>
+//	  <select id="Page" databaseId="sqlserver">...</select>
+//	  <select id="Page">...</select>
+//	  <update id="Touch">UPDATE gc_image SET updated_at = NOW() <where><script name="tenant"/></where></update>
+//	</mapper>
+//
+// 同一id可按 databaseId 定义多个方言的语句，不带 databaseId 的为通用定义，见 Variants
+type Mapper struct {
+	File       string
+	Namespace  string
+	Statements []*MappedStatement
+}
+
+// MappedStatement mapper文件中的一个sql定义
+type MappedStatement struct {
+	ID            string        // 含命名空间的完整id
+	Kind          StatementKind // sql/select/insert/update/delete
+	ResultType    string        // select 结果的go类型
+	ResultMode    string        // select 结果的数量，list（默认）或 one
+	ParameterType string        // 参数的go结构体类型，为空时代码生成按引用的参数生成参数结构体
+	SQL           SQL
+	SelectKey     *SelectKey // insert/update 前后执行的主键生成语句
+}
+
+// KeyOrder 主键生成语句相对插入语句的执行时机
+type KeyOrder string
+
+const (
+	KeyBefore KeyOrder = "BEFORE"
+	KeyAfter  KeyOrder = "AFTER"
+)
+
+// SelectKey mapper中 insert/update 的 selectKey，查询结果赋值给实体的 KeyProperty 属性
+//
+//	<insert id="Insert">
+//	  <selectKey keyProperty="id" order="BEFORE">SELECT nextval('gc_image_id_seq')</selectKey>
+//	  INSERT INTO gc_image (id, md5) VALUES (#{id}, #{md5})
+//	</insert>
+type SelectKey struct {
+	KeyProperty string
+	Order       KeyOrder // 默认 AFTER，与mybatis一致
+	SQL         SQL
+}
+
+// SelectKeyID 语句的主键生成语句在 Collection 中的id
+func SelectKeyID(id string) string {
+	return id + "!selectKey"
+}
+
+// Mappers 多个mapper文件
+type Mappers []*Mapper
+
+// Collection 合并所有mapper中的sql定义，不同文件中定义了相同id时返回错误
+func (ms Mappers) Collection() (Collection, error) {
+	c := Collection{}
+	add := func(id string, sql SQL) error {
+		if _, exist := c[id]; exist {
+			return errors.DuplicateSQL(id)
+		}
+		c[id] = sql
+		return nil
+	}
+	for _, m := range ms {
+		for _, s := range m.Statements {
+			if err := add(s.ID, s.SQL); err != nil {
+				return nil, err
+			}
+			if s.SelectKey == nil {
+				continue
+			}
+			if err := add(SelectKeyID(s.ID), s.SelectKey.SQL); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return c, nil
+}
+
+// Statements 按id排序返回所有sql定义
+func (ms Mappers) Statements() []*MappedStatement {
+	var res []*MappedStatement
+	for _, m := range ms {
+		res = append(res, m.Statements...)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res
+}
+
+// LoadMappers 加载目录下所有 .xml mapper文件
+func LoadMappers(dir string) (Mappers, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.xml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var mappers Mappers
+	for _, file := range files {
+		m, err := LoadMapper(file)
+		if err != nil {
+			return nil, err
+		}
+		mappers = append(mappers, m)
+	}
+	return mappers, nil
+}
+
+// LoadMapper 加载一个mapper文件
+func LoadMapper(file string) (*Mapper, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	m, err := ParseMapper(f)
+	if err != nil {
+		return nil, errors.InvalidMapper(file, err)
+	}
+	m.File = file
+	return m, nil
+}
+
+// ParseMapper 解析mapper文件内容
+func ParseMapper(r io.Reader) (*Mapper, error) {
+	var root xmlNode
+	if err := xml.NewDecoder(r).Decode(&root); err != nil {
+		return nil, err
+	}
+	if root.XMLName.Local != "mapper" {
+		return nil, errors.UnsupportedElement(root.XMLName.Local)
+	}
+
+	p := &mapperParser{namespace: root.attr("namespace")}
+	m := &Mapper{Namespace: p.namespace}
+	defined := map[string]*MappedStatement{}
+	for _, node := range root.Nodes {
+		if node.XMLName.Local == "" {
+			continue
+		}
+		statement, err := p.statement(node)
+		if err != nil {
+			return nil, err
+		}
+
+		// 同一id按 databaseId 定义的多个语句合并为 Variants，不带 databaseId 的为通用定义
+		dialect := Dialect(node.attr("databaseId"))
+		exist, ok := defined[statement.ID]
+		if !ok {
+			if dialect != "" {
+				statement.SQL = Variants(nil, map[Dialect]Elem{dialect: statement.SQL})
+			}
+			defined[statement.ID] = statement
+			m.Statements = append(m.Statements, statement)
+			continue
+		}
+		variants, ok := exist.SQL.(*dialectVariants)
+		if !ok {
+			if dialect == "" {
+				return nil, errors.DuplicateSQL(statement.ID)
+			}
+			exist.SQL = Variants(exist.SQL, map[Dialect]Elem{dialect: statement.SQL})
+			continue
+		}
+		if dialect == "" {
+			if variants.Fallback != nil {
+				return nil, errors.DuplicateSQL(statement.ID)
+			}
+			variants.Fallback = statement.SQL
+		} else {
+			if _, dup := variants.Variants[dialect]; dup {
+				return nil, errors.DuplicateSQL(statement.ID + "@" + string(dialect))
+			}
+			variants.Variants[dialect] = statement.SQL
+		}
+	}
+	return m, nil
+}
+
+// xmlNode 保留文本与子元素顺序的通用xml节点
+type xmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr
+	Nodes   []xmlNode // 子元素及文本，文本节点的 XMLName 为空
+	Text    string
+}
+
+func (n *xmlNode) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	n.XMLName = start.Name
+	n.Attrs = start.Attr
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			var child xmlNode
+			if err = child.UnmarshalXML(d, t); err != nil {
+				return err
+			}
+			n.Nodes = append(n.Nodes, child)
+		case xml.CharData:
+			n.Nodes = append(n.Nodes, xmlNode{Text: string(t)})
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+func (n *xmlNode) attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+type mapperParser struct {
+	namespace string
+	id        string // 当前解析的语句id，用于错误信息
+}
+
+// qualify 不含命名空间的id补全为当前命名空间下的id
+func (p *mapperParser) qualify(id string) string {
+	if p.namespace == "" || strings.Contains(id, ".") {
+		return id
+	}
+	return p.namespace + "." + id
+}
+
+func (p *mapperParser) statement(node xmlNode) (*MappedStatement, error) {
+	kind := StatementKind(node.XMLName.Local)
+	switch kind {
+	case KindSQL, KindSelect, KindInsert, KindUpdate, KindDelete:
+	default:
+		return nil, errors.UnsupportedElement(node.XMLName.Local)
+	}
+
+	id := node.attr("id")
+	if id == "" {
+		return nil, errors.MissingAttribute(node.XMLName.Local, "id")
+	}
+	p.id = p.qualify(id)
+	key, err := p.selectKey(&node)
+	if err != nil {
+		return nil, err
+	}
+	if key != nil && kind != KindInsert && kind != KindUpdate {
+		return nil, errors.UnsupportedElement(node.XMLName.Local + ": selectKey")
+	}
+	children, err := p.children(node)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MappedStatement{
+		ID:            p.qualify(id),
+		Kind:          kind,
+		ResultType:    node.attr("resultType"),
+		ParameterType: node.attr("parameterType"),
+		ResultMode:    node.attr("resultMode"),
+		SQL:           compositeOf(children),
+		SelectKey:     key,
+	}, nil
+}
+
+// selectKey 解析并移除语句中的 selectKey
+func (p *mapperParser) selectKey(node *xmlNode) (*SelectKey, error) {
+	var key *SelectKey
+	nodes := node.Nodes[:0:0]
+	for _, child := range node.Nodes {
+		if child.XMLName.Local != "selectKey" {
+			nodes = append(nodes, child)
+			continue
+		}
+		if key != nil {
+			return nil, errors.DuplicateSQL(p.qualify(node.attr("id")) + ": selectKey")
+		}
+		property := child.attr("keyProperty")
+		if property == "" {
+			return nil, errors.MissingAttribute("selectKey", "keyProperty")
+		}
+		order := KeyOrder(strings.ToUpper(child.attr("order")))
+		switch order {
+		case "":
+			order = KeyAfter
+		case KeyBefore, KeyAfter:
+		default:
+			return nil, errors.UnsupportedElement("selectKey: order=" + string(order))
+		}
+		children, err := p.children(child)
+		if err != nil {
+			return nil, err
+		}
+		key = &SelectKey{KeyProperty: property, Order: order, SQL: compositeOf(children)}
+	}
+	node.Nodes = nodes
+	return key, nil
+}
+
+func (p *mapperParser) children(node xmlNode) ([]Elem, error) {
+	var elems []Elem
+	for _, child := range node.Nodes {
+		if child.XMLName.Local == "" {
+			if strings.TrimSpace(child.Text) != "" {
+				elems = append(elems, Frag(child.Text))
+			}
+			continue
+		}
+		e, err := p.elem(child)
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, e)
+	}
+	return elems, nil
+}
+
+func (p *mapperParser) elem(node xmlNode) (Elem, error) {
+	name := node.XMLName.Local
+	switch name {
+	case "include":
+		return p.include(node)
+	case "script":
+		ref := node.attr("name")
+		if ref == "" {
+			return nil, errors.MissingAttribute(name, "name")
+		}
+		return ScriptRef(ref), nil
+	}
+
+	children, err := p.children(node)
+	if err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case "if", "when":
+		test := node.attr("test")
+		if test == "" {
+			return nil, errors.MissingAttribute(name, "test")
+		}
+		cond, err := ParseTest(test)
+		if err != nil {
+			return nil, errors.InvalidMapper(p.id, err)
+		}
+		return &_if{Condition: cond, Children: children}, nil
+	case "otherwise":
+		return &_if{Condition: True(), Children: children}, nil
+	case "choose":
+		branches := make([]ConditionElem, 0, len(children))
+		for _, child := range children {
+			branch, ok := child.(ConditionElem)
+			if !ok {
+				return nil, errors.UnsupportedElement("choose: text")
+			}
+			branches = append(branches, branch)
+		}
+		return Choose(branches...), nil
+	case "where":
+		return Where(children...), nil
+	case "set":
+		return Set(children...), nil
+	case "trim":
+		return Trim(
+			node.attr("prefix"),
+			splitOverrides(node.attr("prefixOverrides")),
+			splitOverrides(node.attr("suffixOverrides")),
+			children...,
+		), nil
+	}
+	return nil, errors.UnsupportedElement(name)
+}
+
+func (p *mapperParser) include(node xmlNode) (Elem, error) {
+	refid := node.attr("refid")
+	if refid == "" {
+		return nil, errors.MissingAttribute("include", "refid")
+	}
+
+	props := MapParameters{}
+	for _, child := range node.Nodes {
+		if child.XMLName.Local == "" {
+			continue
+		}
+		if child.XMLName.Local != "property" {
+			return nil, errors.UnsupportedElement("include: " + child.XMLName.Local)
+		}
+		props[child.attr("name")] = child.attr("value")
+	}
+
+	if strings.HasPrefix(refid, "${") && strings.HasSuffix(refid, "}") {
+		return Include(strings.TrimSpace(refid[2:len(refid)-1]), true, props), nil
+	}
+	return Include(p.qualify(refid), false, props), nil
+}
+
+// splitOverrides 解析 prefixOverrides/suffixOverrides，多个值以 | 分隔
+func splitOverrides(s string) []string {
+	if s == "" {
+		return nil
+	}
+	var res []string
+	for _, o := range strings.Split(s, "|") {
+		if o = strings.TrimSpace(o); o != "" {
+			res = append(res, o)
+		}
+	}
+	return res
+}
+
+func compositeOf(elems []Elem) Elem {
+	if len(elems) == 1 {
+		return elems[0]
+	}
+	return &composite{Children: elems}
+}
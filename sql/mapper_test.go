@@ -0,0 +1,120 @@
+package sql
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testMapper = `
+<mapper namespace="Image">
+  <sql id="Fields">${alias}.id, ${alias}.md5</sql>
+  <select id="Search" resultType="Image">
+    SELECT <include refid="Fields"><property name="alias" value="gi"/></include>
+    FROM gc_image gi
+    <where>
+      <if test=".state">gi.state = #{state}</if>
+      <if test=".idList">AND gi.id IN (#{idList})</if>
+    </where>
+  </select>
+</mapper>`
+
+func TestMapper(t *testing.T) {
+	m, err := ParseMapper(strings.NewReader(testMapper))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "Image", m.Namespace)
+	if assert.Len(t, m.Statements, 2) {
+		assert.Equal(t, "Image.Fields", m.Statements[0].ID)
+		assert.Equal(t, KindSQL, m.Statements[0].Kind)
+		assert.Equal(t, "Image.Search", m.Statements[1].ID)
+		assert.Equal(t, "Image", m.Statements[1].ResultType)
+	}
+
+	collection, err := Mappers{m}.Collection()
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctx := NewContext().
+		WithCollection(collection).
+		WithParams(MapParameters{"state": 1, "idList": []int64{1, 2, 3}}).
+		WithFormat(FormatCompact)
+	stmt, args, err := PrepareID(ctx, "Image.Search").Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT gi.id, gi.md5 FROM gc_image gi WHERE gi.state = ? AND gi.id IN (?, ?, ?)`, stmt)
+		assert.Equal(t, []any{1, int64(1), int64(2), int64(3)}, args)
+	}
+
+	_, err = ParseMapper(strings.NewReader(`<mapper><select id="x"><foreach/></select></mapper>`))
+	assert.EqualError(t, err, "unsupported element: foreach")
+
+	// 不合法的条件表达式返回错误而不是panic
+	_, err = ParseMapper(strings.NewReader(`<mapper namespace="Image"><select id="x"><if test=".a &amp;&amp; .b">1</if></select></mapper>`))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid mapper Image.x")
+	}
+}
+
+func TestMappersDuplicateID(t *testing.T) {
+	a, err := ParseMapper(strings.NewReader(`<mapper namespace="Image"><select id="Get">SELECT 1</select></mapper>`))
+	assert.NoError(t, err)
+	b, err := ParseMapper(strings.NewReader(`<mapper namespace="Image"><select id="Get">SELECT 2</select></mapper>`))
+	assert.NoError(t, err)
+
+	_, err = Mappers{a, b}.Collection()
+	assert.EqualError(t, err, "duplicate sql: Image.Get")
+}
+
+func TestLoadMappersInvalidTest(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "Image.xml"), []byte(`<mapper namespace="Image">
+  <select id="Search"><where><when test=".a &amp;&amp; .b">AND a = #{a}</when></where></select>
+</mapper>`), 0o644)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotPanics(t, func() {
+		_, err = LoadMappers(dir)
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Image.Search")
+	}
+}
+
+func TestMapperSelectKey(t *testing.T) {
+	m, err := ParseMapper(strings.NewReader(`
+<mapper namespace="Image">
+  <insert id="Insert">
+    <selectKey keyProperty="id" order="before">SELECT nextval('gc_image_id_seq')</selectKey>
+    INSERT INTO gc_image (id, md5) VALUES (#{id}, #{md5})
+  </insert>
+</mapper>`))
+	if !assert.NoError(t, err) || !assert.Len(t, m.Statements, 1) {
+		return
+	}
+	key := m.Statements[0].SelectKey
+	if assert.NotNil(t, key) {
+		assert.Equal(t, "id", key.KeyProperty)
+		assert.Equal(t, KeyBefore, key.Order)
+	}
+
+	collection, err := Mappers{m}.Collection()
+	if !assert.NoError(t, err) {
+		return
+	}
+	stmt, _, err := PrepareID(NewContext().WithCollection(collection).Named().WithFormat(FormatCompact), "Image.Insert").Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `INSERT INTO gc_image (id, md5) VALUES (:id, :md5)`, stmt)
+	}
+	stmt, _, err = PrepareID(NewContext().WithCollection(collection), SelectKeyID("Image.Insert")).Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT nextval('gc_image_id_seq')`, stmt)
+	}
+
+	_, err = ParseMapper(strings.NewReader(`<mapper><select id="x"><selectKey keyProperty="id">SELECT 1</selectKey>SELECT 1</select></mapper>`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,128 @@
+package sql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/non1996/go-batis/errors"
+)
+
+// OrderBy 由参数中的排序描述生成 ORDER BY，排序描述如 name,-created_at，- 前缀表示降序，
+// 也可写作 name:desc、name desc。allowed 为公开排序键到实际列的映射，不在其中的排序键报错，
+// 参数为空时按默认排序描述defaults排序，defaults 也为空时不输出
+func OrderBy(param string, allowed map[string]string, defaults ...string) Elem {
+	return &orderBy{
+		Param:    param,
+		Allowed:  allowed,
+		Defaults: defaults,
+	}
+}
+
+type orderBy struct {
+	Param    string
+	Allowed  map[string]string
+	Defaults []string
+}
+
+func (s *orderBy) Evaluate(ctx *Context) (statement *Statement, err error) {
+	node, err := ctx.enter(s)
+	defer func() { ctx.leave(node, statement, err) }()
+	if err != nil {
+		return nil, err
+	}
+
+	specs, err := s.specs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(strings.Join(specs, "")) == "" {
+		specs = s.Defaults
+	}
+
+	var orders []string
+	for _, spec := range specs {
+		for _, item := range strings.Split(spec, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			order, err := s.order(item)
+			if err != nil {
+				return nil, err
+			}
+			orders = append(orders, order)
+		}
+	}
+	if len(orders) == 0 {
+		return emptyStatement, nil
+	}
+	return NewStatement("ORDER BY "+strings.Join(orders, ", "), nil), nil
+}
+
+// specs 参数中的排序描述，支持 string、*string、[]string，参数不存在时视为空
+func (s *orderBy) specs(ctx *Context) ([]string, error) {
+	value, exist := ctx.Lookup(s.Param)
+	if !exist {
+		return nil, nil
+	}
+	switch spec := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{spec}, nil
+	case *string:
+		if spec == nil {
+			return nil, nil
+		}
+		return []string{*spec}, nil
+	case []string:
+		return spec, nil
+	}
+	return nil, errors.InvalidParameter(s.Param, fmt.Sprintf("expect string or []string, got %T", value))
+}
+
+// order 将一个排序描述转换为 列 ASC/DESC
+func (s *orderBy) order(item string) (string, error) {
+	key, direction := item, "ASC"
+	switch {
+	case strings.HasPrefix(item, "-"):
+		key, direction = item[1:], "DESC"
+	case strings.HasPrefix(item, "+"):
+		key = item[1:]
+	default:
+		if k, d, found := strings.Cut(strings.Replace(item, ":", " ", 1), " "); found {
+			key, direction = k, strings.ToUpper(strings.TrimSpace(d))
+			if direction != "ASC" && direction != "DESC" {
+				return "", errors.InvalidSort(s.Param, item, "has invalid direction")
+			}
+		}
+	}
+
+	column, ok := s.Allowed[strings.TrimSpace(key)]
+	if !ok {
+		return "", errors.InvalidSort(s.Param, item, "is not allowed")
+	}
+	return column + " " + direction, nil
+}
+
+func (s *orderBy) inspect(w *walker) error {
+	w.param(s.Param)
+	return nil
+}
+
+func (s *orderBy) goSource() (string, error) {
+	keys := make([]string, 0, len(s.Allowed))
+	for k := range s.Allowed {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	allowed := make([]string, 0, len(keys))
+	for _, k := range keys {
+		allowed = append(allowed, fmt.Sprintf("%q: %q", k, s.Allowed[k]))
+	}
+	var defaults string
+	for _, d := range s.Defaults {
+		defaults += ", " + goString(d)
+	}
+	return fmt.Sprintf("sql.OrderBy(%q, map[string]string{%s}%s)", s.Param, strings.Join(allowed, ", "), defaults), nil
+}
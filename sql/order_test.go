@@ -0,0 +1,50 @@
+package sql
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/non1996/go-batis/errors"
+)
+
+func TestOrderBy(t *testing.T) {
+	e := Composite(`SELECT * FROM gc_image gi`, OrderBy("sort", map[string]string{
+		"name":      "gi.name",
+		"createdAt": "gi.created_at",
+	}, "-createdAt"))
+	render := func(sort any) (string, error) {
+		stmt, _, err := Prepare(NewContext().WithParams(MapParameters{"sort": sort}), e).Prepare()
+		return stmt, err
+	}
+
+	stmt, err := render("name, -createdAt")
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM gc_image gi ORDER BY gi.name ASC, gi.created_at DESC`, stmt)
+	}
+	stmt, err = render([]string{"createdAt:asc", "name desc"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM gc_image gi ORDER BY gi.created_at ASC, gi.name DESC`, stmt)
+	}
+
+	// 为空时使用默认排序
+	stmt, err = render("")
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM gc_image gi ORDER BY gi.created_at DESC`, stmt)
+	}
+	stmt, _, err = Prepare(NewContext(), OrderBy("sort", map[string]string{"name": "name"})).Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, ``, stmt)
+	}
+
+	var sortErr *errors.SortError
+	_, err = render("name; DROP TABLE gc_image")
+	if assert.True(t, stderrors.As(err, &sortErr)) {
+		assert.Equal(t, "name; DROP TABLE gc_image", sortErr.Key)
+	}
+	_, err = render("name sideways")
+	if assert.True(t, stderrors.As(err, &sortErr)) {
+		assert.Equal(t, "has invalid direction", sortErr.Reason)
+	}
+}
@@ -0,0 +1,170 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/non1996/go-batis/errors"
+)
+
+// PageRequest 分页参数，Page 从1开始
+type PageRequest struct {
+	Page int64 `json:"page"`
+	Size int64 `json:"size"`
+}
+
+// Offset 分页偏移量，Page 小于1时按第一页处理
+func (p PageRequest) Offset() int64 {
+	if p.Page < 1 {
+		return 0
+	}
+	return (p.Page - 1) * p.Size
+}
+
+// Paginate 按方言输出分页子句，offset、limit 为参数名：
+// MySQL/PostgreSQL/SQLite 输出 LIMIT ? OFFSET ?，SQL Server/Oracle 输出 OFFSET ? ROWS FETCH NEXT ? ROWS ONLY
+func Paginate(offset, limit string) Elem {
+	return &paginate{
+		Offset: offset,
+		Limit:  limit,
+	}
+}
+
+// Page 按方言输出分页子句，pageParam 为 PageRequest 类型的参数名，偏移量由页码推导
+func Page(pageParam string) Elem {
+	return &paginate{
+		Page: pageParam,
+	}
+}
+
+// paginate 分页子句，求值计数语句时不输出
+type paginate struct {
+	Offset string
+	Limit  string
+	Page   string
+}
+
+func (s *paginate) Evaluate(ctx *Context) (statement *Statement, err error) {
+	node, err := ctx.enter(s)
+	defer func() { ctx.leave(node, statement, err) }()
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.counting {
+		return emptyStatement, nil
+	}
+
+	statement = &Statement{}
+	var offset, limit string // 参数名
+	if s.Page != "" {
+		page, err := s.pageRequest(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ctx.recordPage(page)
+		offset = ctx.bind(statement, page.Offset())
+		limit = ctx.bind(statement, page.Size)
+	} else {
+		offset, limit = s.Offset, s.Limit
+		s.recordOffset(ctx)
+	}
+
+	mark := func(name string) string {
+		if ctx.named {
+			return ":" + name
+		}
+		return "?"
+	}
+	switch ctx.dialect {
+	case SQLServer, Oracle:
+		statement.Stmt = "OFFSET " + mark(offset) + " ROWS FETCH NEXT " + mark(limit) + " ROWS ONLY"
+		statement.ArgNames = []string{offset, limit}
+	default:
+		statement.Stmt = "LIMIT " + mark(limit) + " OFFSET " + mark(offset)
+		statement.ArgNames = []string{limit, offset}
+	}
+	return statement, nil
+}
+
+func (s *paginate) pageRequest(ctx *Context) (PageRequest, error) {
+	value, exist := ctx.Lookup(s.Page)
+	if !exist {
+		return PageRequest{}, errors.MissingParameter(s.Page)
+	}
+	switch page := value.(type) {
+	case PageRequest:
+		return page, nil
+	case *PageRequest:
+		if page != nil {
+			return *page, nil
+		}
+	}
+	return PageRequest{}, errors.InvalidParameter(s.Page, fmt.Sprintf("expect sql.PageRequest, got %T", value))
+}
+
+// recordOffset 按偏移量记录分页参数，参数不是整数时不记录
+func (s *paginate) recordOffset(ctx *Context) {
+	if ctx.page == nil {
+		return
+	}
+	offsetValue, _ := ctx.Lookup(s.Offset)
+	limitValue, _ := ctx.Lookup(s.Limit)
+	offset, ok1 := toInt64(offsetValue)
+	limit, ok2 := toInt64(limitValue)
+	if !ok1 || !ok2 || limit <= 0 {
+		return
+	}
+	ctx.recordPage(PageRequest{Page: offset/limit + 1, Size: limit})
+}
+
+func (c *Context) recordPage(page PageRequest) {
+	if c.page != nil {
+		*c.page = page
+	}
+}
+
+func toInt64(v any) (int64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return int64(rv.Float()), true
+	}
+	return 0, false
+}
+
+func (s *paginate) inspect(w *walker) error {
+	if s.Page != "" {
+		w.param(s.Page)
+		return nil
+	}
+	w.param(s.Offset)
+	w.param(s.Limit)
+	return nil
+}
+
+func (s *paginate) goSource() (string, error) {
+	if s.Page != "" {
+		return fmt.Sprintf("sql.Page(%q)", s.Page), nil
+	}
+	return fmt.Sprintf("sql.Paginate(%q, %q)", s.Offset, s.Limit), nil
+}
+
+// Count 派生计数语句，见 Count
+func (p *Prepared) Count() *Prepared {
+	return Prepare(p.ctx, Count(p.elem))
+}
+
+// PreparePage 同 PrepareContext，并返回语句中分页元素使用的分页参数，没有分页元素时返回零值
+func (p *Prepared) PreparePage(ctx context.Context) (string, []any, PageRequest, error) {
+	var page PageRequest
+	c := *p.ctx
+	c.page = &page
+	stmt, args, err := Prepare(&c, p.elem).PrepareContext(ctx)
+	return stmt, args, page, err
+}
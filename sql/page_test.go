@@ -0,0 +1,58 @@
+package sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPage(t *testing.T) {
+	query := Composite(`SELECT * FROM gc_image`, Where(If(Test(".md5"), `md5 = #{md5}`)), `ORDER BY id`, Page("page"))
+	params := MapParameters{"md5": "abc", "page": PageRequest{Page: 3, Size: 20}}
+
+	stmt, args, page, err := Prepare(NewContext().WithParams(params).WithDialect(MySQL), query).PreparePage(context.Background())
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM gc_image WHERE md5 = ? ORDER BY id LIMIT ? OFFSET ?`, stmt)
+		assert.Equal(t, []any{"abc", int64(20), int64(40)}, args)
+		assert.Equal(t, PageRequest{Page: 3, Size: 20}, page)
+	}
+
+	stmt, args, err = Prepare(NewContext().WithParams(params).WithDialect(SQLServer), query).Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM gc_image WHERE md5 = @p1 ORDER BY id OFFSET @p2 ROWS FETCH NEXT @p3 ROWS ONLY`, stmt)
+		assert.Equal(t, []any{"abc", int64(40), int64(20)}, args)
+	}
+
+	// 计数语句不含分页子句
+	stmt, args, err = Prepare(NewContext().WithParams(params).WithDialect(PostgreSQL), query).Count().Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT COUNT(*) FROM gc_image WHERE md5 = $1`, stmt)
+		assert.Equal(t, []any{"abc"}, args)
+	}
+
+	_, _, err = Prepare(NewContext().WithParams(MapParameters{"page": 1}), Page("page")).Prepare()
+	assert.Error(t, err)
+}
+
+func TestPaginate(t *testing.T) {
+	query := Composite(`SELECT * FROM gc_image`, Paginate("offset", "limit"))
+	params := MapParameters{"offset": 10, "limit": 5}
+
+	stmt, args, page, err := Prepare(NewContext().WithParams(params).WithDialect(SQLite), query).PreparePage(context.Background())
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM gc_image LIMIT ? OFFSET ?`, stmt)
+		assert.Equal(t, []any{5, 10}, args)
+		assert.Equal(t, PageRequest{Page: 3, Size: 5}, page)
+	}
+
+	stmt, _, err = Prepare(NewContext().WithParams(params).WithDialect(Oracle), query).Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM gc_image OFFSET :1 ROWS FETCH NEXT :2 ROWS ONLY`, stmt)
+	}
+
+	src, err := GoSource(query)
+	if assert.NoError(t, err) {
+		assert.Contains(t, src, `sql.Paginate("offset", "limit")`)
+	}
+}
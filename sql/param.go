@@ -0,0 +1,116 @@
+package sql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ParamOptions 占位符 #{name,key=value,...} 中的参数名及选项，与mybatis一致：
+//
+//	#{meta,type=json}         按名为json的类型处理器转换
+//	#{ts,handler=unixmillis}  同上，handler 优先于 type
+//	#{ids,expand=false}       切片不展开为多个占位符，作为单个值传递（如 PostgreSQL 数组）
+//	#{limit,default=20}       参数不存在时使用默认值
+type ParamOptions struct {
+	Name    string
+	Type    string
+	Handler string
+	Expand  *bool   // nil 表示默认行为，即切片展开
+	Default *string // 默认值文本，见 DefaultValue
+}
+
+// ParseParam 解析占位符内容，未知选项忽略，引号中的逗号不作为选项分隔符，如 #{tags,default='a,b'}
+func ParseParam(spec string) ParamOptions {
+	name, options, _ := strings.Cut(spec, ",")
+	opts := ParamOptions{Name: strings.TrimSpace(name)}
+	if options == "" {
+		return opts
+	}
+	for _, option := range splitOptions(options) {
+		k, v, _ := strings.Cut(option, "=")
+		v = strings.TrimSpace(v)
+		switch strings.TrimSpace(k) {
+		case "type":
+			opts.Type = v
+		case "handler":
+			opts.Handler = v
+		case "expand":
+			expand := v == "" || v == "true"
+			opts.Expand = &expand
+		case "default":
+			opts.Default = &v
+		}
+	}
+	return opts
+}
+
+// splitOptions 按逗号切分选项，跳过单引号或双引号中的逗号
+func splitOptions(s string) []string {
+	var options []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ',':
+			options = append(options, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(options, s[start:])
+}
+
+// TypeHandler 显式指定的类型处理器名，handler 优先于 type
+func (o ParamOptions) TypeHandler() string {
+	if o.Handler != "" {
+		return o.Handler
+	}
+	return o.Type
+}
+
+// Expandable 切片参数是否展开为多个占位符
+func (o ParamOptions) Expandable() bool {
+	return o.Expand == nil || *o.Expand
+}
+
+// DefaultValue 默认值，整数、浮点数、true/false、null 按对应类型解析，引号包裹或其他文本为字符串
+func (o ParamOptions) DefaultValue() (any, bool) {
+	if o.Default == nil {
+		return nil, false
+	}
+	v := *o.Default
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return n, true
+	}
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		return f, true
+	}
+	switch strings.ToLower(v) {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	case "null":
+		return nil, true
+	}
+	if len(v) >= 2 && (v[0] == '\'' || v[0] == '"') && v[len(v)-1] == v[0] {
+		return v[1 : len(v)-1], true
+	}
+	return v, true
+}
+
+// lookupParam 按选项查找参数值，依次查找语句推导的参数、上下文参数及默认值
+func (c *Context) lookupParam(opts ParamOptions, bindings MapParameters) (any, bool) {
+	if value, exist := bindings[opts.Name]; exist {
+		return value, true
+	}
+	if value, exist := c.Lookup(opts.Name); exist {
+		return value, true
+	}
+	return opts.DefaultValue()
+}
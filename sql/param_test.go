@@ -0,0 +1,60 @@
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParamOptions(t *testing.T) {
+	opts := ParseParam(" ids , expand=false, default='a' ")
+	assert.Equal(t, "ids", opts.Name)
+	assert.False(t, opts.Expandable())
+	v, ok := opts.DefaultValue()
+	assert.True(t, ok)
+	assert.Equal(t, "a", v)
+	assert.Equal(t, "json", ParseParam("meta,type=json").TypeHandler())
+
+	// 引号中的逗号
+	opts = ParseParam(`tags, default='a,b', expand=false`)
+	v, _ = opts.DefaultValue()
+	assert.Equal(t, "a,b", v)
+	assert.False(t, opts.Expandable())
+	v, _ = ParseParam(`sep,default=","`).DefaultValue()
+	assert.Equal(t, ",", v)
+
+	// 具名模式下无法补充默认值
+	_, _, err := Prepare(NewContext().Named(), Frag(`LIMIT #{limit,default=20}`)).Prepare()
+	assert.EqualError(t, err, "default values (limit) are not supported in named mode")
+	assert.Equal(t, "unixmillis", ParseParam("ts,type=json,handler=unixmillis").TypeHandler())
+
+	ts := time.UnixMilli(1700000000000)
+	e := Frag(`SELECT * FROM t WHERE tags && #{tags,expand=false} AND id IN (#{ids,expand=true}) AND meta = #{meta,type=json} AND ts > #{ts,handler=unixmillis} LIMIT #{limit,default=20}`)
+	statement, err := NewContext().WithParams(MapParameters{
+		"tags": []string{"a", "b"},
+		"ids":  []int{1, 2},
+		"meta": map[string]int{"w": 1},
+		"ts":   ts,
+	}).Evaluate(e)
+	if !assert.NoError(t, err) {
+		return
+	}
+	args := statement.Args()
+	if assert.Len(t, args, 5) {
+		assert.Equal(t, "tags", args[0].Name)
+		assert.Equal(t, "json", args[2].Type)
+		assert.Equal(t, "unixmillis", args[3].Handler)
+	}
+
+	stmt, values, err := NewContext().WithParams(MapParameters{
+		"tags": []string{"a", "b"},
+		"ids":  []int{1, 2},
+		"meta": map[string]int{"w": 1},
+		"ts":   ts,
+	}).WithDialect(PostgreSQL).Resolve(statement)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM t WHERE tags && $1 AND id IN ($2, $3) AND meta = $4 AND ts > $5 LIMIT $6`, stmt)
+		assert.Equal(t, []any{[]string{"a", "b"}, 1, 2, `{"w":1}`, int64(1700000000000), int64(20)}, values)
+	}
+}
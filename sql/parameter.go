@@ -1,6 +1,11 @@
 package sql
 
 import (
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
 	"github.com/non1996/go-jsonobj/container"
 )
 
@@ -22,6 +27,56 @@ func MergeParameters(p1, p2 Parameters) Parameters {
 	return newP
 }
 
+// Scope 分层参数，查找时先查子作用域再查父作用域，不复制任何参数。
+// 用于 Context.Next，避免大参数对象在多层 include 中被反复复制
+func Scope(parent, child Parameters) Parameters {
+	if m, ok := child.(MapParameters); ok && len(m) == 0 {
+		return parent
+	}
+	return &scopeParameters{parent: parent, child: child}
+}
+
+type scopeParameters struct {
+	parent Parameters
+	child  Parameters
+
+	once sync.Once
+	flat MapParameters
+}
+
+// view 合并各层参数的浅拷贝，首次使用时构造，用作 Test 条件的模板数据以支持 index、$ 等写法
+func (s *scopeParameters) view() MapParameters {
+	s.once.Do(func() {
+		s.flat = MapParameters{}
+		for _, k := range s.Keys() {
+			s.flat[k] = s.Get(k)
+		}
+	})
+	return s.flat
+}
+
+func (s *scopeParameters) Get(key string) any {
+	if s.child.Exist(key) {
+		return s.child.Get(key)
+	}
+	return s.parent.Get(key)
+}
+
+func (s *scopeParameters) Exist(key string) bool {
+	return s.child.Exist(key) || s.parent.Exist(key)
+}
+
+// Keys 子作用域的键在前，父作用域中被覆盖的键不重复返回
+func (s *scopeParameters) Keys() []string {
+	keys := slices.Clip(s.child.Keys()) // 避免修改子作用域返回的切片
+	for _, k := range s.parent.Keys() {
+		if !s.child.Exist(k) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
 type MapParameters map[string]any
 
 func (m MapParameters) Get(key string) any {
@@ -35,3 +90,126 @@ func (m MapParameters) Exist(key string) bool {
 func (m MapParameters) Keys() []string {
 	return container.MapKeys(m)
 }
+
+// StructParameters 以结构体（或其指针）的字段作为参数，参数名为 db 标签，没有标签时为字段名（不区分大小写），
+// 嵌入结构体的字段同样可引用。值为nil的指针或接口字段视为参数不存在，可配合 If(Test(".x")) 及
+// #{x,default=...} 表示可选参数，非nil的指针字段取其指向的值
+func StructParameters(v any) Parameters {
+	return &structParameters{v: reflect.Indirect(reflect.ValueOf(v))}
+}
+
+type structParameters struct {
+	v reflect.Value
+}
+
+func (p *structParameters) field(key string) (reflect.Value, bool) {
+	f, ok := StructField(p.v, key)
+	if !ok {
+		return reflect.Value{}, false
+	}
+	switch f.Kind() {
+	case reflect.Pointer:
+		if f.IsNil() {
+			return reflect.Value{}, false
+		}
+		return f.Elem(), true
+	case reflect.Interface:
+		if f.IsNil() {
+			return reflect.Value{}, false
+		}
+	}
+	return f, true
+}
+
+func (p *structParameters) Get(key string) any {
+	if f, ok := p.field(key); ok {
+		return f.Interface()
+	}
+	return nil
+}
+
+func (p *structParameters) Exist(key string) bool {
+	_, ok := p.field(key)
+	return ok
+}
+
+func (p *structParameters) Keys() []string {
+	var keys []string
+	for _, name := range structFieldNames(p.v) {
+		if p.Exist(name) {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
+
+// structFieldName 字段的参数名，db 标签为 - 时忽略
+func structFieldName(f reflect.StructField) (string, bool) {
+	tag, _, _ := strings.Cut(f.Tag.Get("db"), ",")
+	switch tag {
+	case "-":
+		return "", false
+	case "":
+		return f.Name, true
+	}
+	return tag, true
+}
+
+// StructField 按 db 标签或字段名（不区分大小写）查找导出字段，db 标签为 - 的字段忽略，
+// 先查找当前结构体，再查找嵌入结构体（nil 指针跳过）。结果映射、Seek、selectKey 等同样按此规则查找字段
+func StructField(v reflect.Value, name string) (reflect.Value, bool) {
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		if fieldName, ok := structFieldName(f); ok && strings.EqualFold(fieldName, name) {
+			return v.Field(i), true
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if f, ok := StructField(embeddedStruct(v, i), name); ok {
+			return f, true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// structFieldNames 全部导出字段的参数名，含嵌入结构体
+func structFieldNames(v reflect.Value) []string {
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	var names []string
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			names = append(names, structFieldNames(embeddedStruct(v, i))...)
+			continue
+		}
+		if name, ok := structFieldName(f); ok && f.IsExported() {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// embeddedStruct 第i个字段为嵌入结构体（或非nil指针）时返回其值
+func embeddedStruct(v reflect.Value, i int) reflect.Value {
+	if !v.Type().Field(i).Anonymous {
+		return reflect.Value{}
+	}
+	embedded := v.Field(i)
+	if embedded.Kind() == reflect.Pointer {
+		if embedded.IsNil() {
+			return reflect.Value{}
+		}
+		embedded = embedded.Elem()
+	}
+	return embedded
+}
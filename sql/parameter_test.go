@@ -0,0 +1,130 @@
+package sql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScope(t *testing.T) {
+	parent := MapParameters{"a": 1, "b": 2}
+	child := MapParameters{"b": 3, "c": 4}
+	scope := Scope(parent, child)
+
+	assert.Equal(t, 1, scope.Get("a"))
+	assert.Equal(t, 3, scope.Get("b"))
+	assert.Equal(t, 4, scope.Get("c"))
+	assert.True(t, scope.Exist("a"))
+	assert.False(t, scope.Exist("d"))
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, scope.Keys())
+
+	// 空的子作用域不增加层级
+	assert.Equal(t, parent, Scope(parent, MapParameters{}))
+	// 父参数不被修改
+	assert.Equal(t, MapParameters{"a": 1, "b": 2}, parent)
+}
+
+func TestScopeCondition(t *testing.T) {
+	c := Collection{
+		"Cond": Composite(
+			If(Test(`index . "state"`), `${alias}.state = #{state}`),
+			If(Test(`$.md5`), `${alias}.md5 = #{md5}`),
+		),
+		"Select": Include("Cond", false, MapParameters{"alias": "t"}),
+	}
+	params := MapParameters{"alias": "t", "state": 1, "md5": "x"}
+
+	// 直接调用与经过带属性的 include 调用结果一致
+	direct, _, err := PrepareID(NewContext().WithCollection(c).WithParams(params).WithFormat(FormatCompact), "Cond").Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `t.state = ? t.md5 = ?`, direct)
+	}
+	delete(params, "alias")
+	included, args, err := PrepareID(NewContext().WithCollection(c).WithParams(params).WithFormat(FormatCompact), "Select").Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, direct, included)
+		assert.Equal(t, []any{1, "x"}, args)
+	}
+}
+
+func largeParameters(n int) MapParameters {
+	params := MapParameters{}
+	for i := 0; i < n; i++ {
+		params[fmt.Sprintf("key%d", i)] = i
+	}
+	return params
+}
+
+// nested 模拟多层 include 逐层传递属性
+func nested(ctx *Context, depth int) *Context {
+	for i := 0; i < depth; i++ {
+		ctx = ctx.Next(MapParameters{fmt.Sprintf("prop%d", i): i})
+	}
+	return ctx
+}
+
+func BenchmarkContextNext(b *testing.B) {
+	params := largeParameters(1000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		nested(NewContext().WithParams(params), 8)
+	}
+}
+
+func BenchmarkMergeParameters(b *testing.B) {
+	params := largeParameters(1000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var p Parameters = params
+		for d := 0; d < 8; d++ {
+			p = MergeParameters(p, MapParameters{fmt.Sprintf("prop%d", d): d})
+		}
+	}
+}
+
+func BenchmarkScopeLookup(b *testing.B) {
+	ctx := nested(NewContext().WithParams(largeParameters(1000)), 8)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ctx.Lookup("key500")
+		ctx.Lookup("prop0")
+	}
+}
+
+type testQueryBase struct {
+	Tenant int64 `db:"tenant_id"`
+}
+
+type testQuery struct {
+	testQueryBase
+	State  *int
+	Md5    string `db:"md5"`
+	Ignore string `db:"-"`
+	secret string
+}
+
+func TestStructParameters(t *testing.T) {
+	state := 2
+	params := StructParameters(&testQuery{testQueryBase: testQueryBase{Tenant: 7}, State: &state, Md5: "abc", secret: "x"})
+	assert.Equal(t, 2, params.Get("state"))
+	assert.Equal(t, "abc", params.Get("md5"))
+	assert.Equal(t, int64(7), params.Get("tenant_id"))
+	assert.False(t, params.Exist("Ignore"))
+	assert.False(t, params.Exist("secret"))
+	assert.Equal(t, []string{"tenant_id", "State", "md5"}, params.Keys())
+
+	// nil指针字段视为参数不存在
+	e := Composite(`SELECT * FROM gc_image`, Where(If(Test(".state"), `state = #{state}`), Frag(`AND md5 = #{md5}`)), `LIMIT #{limit,default=10}`)
+	stmt, args, err := Prepare(NewContext().WithParams(StructParameters(testQuery{Md5: "abc"})), e).Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM gc_image WHERE md5 = ? LIMIT ?`, stmt)
+		assert.Equal(t, []any{"abc", int64(10)}, args)
+	}
+	stmt, args, err = Prepare(NewContext().WithParams(params), e).Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM gc_image WHERE state = ? AND md5 = ? LIMIT ?`, stmt)
+		assert.Equal(t, []any{2, "abc", int64(10)}, args)
+	}
+}
@@ -0,0 +1,170 @@
+package sql
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/non1996/go-batis/errors"
+)
+
+// Prepared 绑定了上下文的sql元素，实现 implement.Statement，执行时才求值
+type Prepared struct {
+	ctx  *Context
+	elem Elem
+}
+
+// Prepare 将sql元素与上下文绑定，交给 implement 中的 List/Get/Exec 等函数执行
+func Prepare(ctx *Context, e Elem) *Prepared {
+	return &Prepared{ctx: ctx, elem: e}
+}
+
+// PrepareID 将上下文中id对应的sql定义与上下文绑定，定义中的相对引用按其所在命名空间解析
+func PrepareID(ctx *Context, id string) *Prepared {
+	fullID, sql := ctx.mustResolve(id)
+	return Prepare(ctx.WithNamespace(namespaceOf(fullID)), sql)
+}
+
+// Prepare 求值并解析参数值，返回可直接执行的语句及参数
+func (p *Prepared) Prepare() (string, []any, error) {
+	statement, err := p.ctx.Evaluate(p.elem)
+	if err != nil {
+		return "", nil, err
+	}
+	return p.ctx.Resolve(statement)
+}
+
+// PrepareContext 同 Prepare，求值时使用请求的 context.Context，implement 中的函数会传入session
+func (p *Prepared) PrepareContext(ctx context.Context) (string, []any, error) {
+	c := *p.ctx
+	return Prepare(c.WithContext(ctx), p.elem).Prepare()
+}
+
+// Evaluate 已渲染的语句求值结果即其自身，因此可作为预渲染的sql定义使用
+func (s *Statement) Evaluate(ctx *Context) (*Statement, error) {
+	return s, nil
+}
+
+// Resolve 从上下文参数中取出语句各参数的值并按类型处理器转换，切片类型的参数展开为多个占位符，用于 IN 列表，
+// 占位符选项见 ParamOptions。字符串字面量及注释中的 ? 不是占位符；
+// 运算符 ? 写作 ??（如 data ?? 'key'、data ??| array['a']），带序号占位符的方言输出为 ?，其他方言原样输出。
+// 具名模式下参数由调用方提供，元素推导出的参数（如分页偏移量）无法传递，指定或按参数名注册了类型处理器的参数无法转换，
+// 默认值无法补充，均返回错误；按Go类型注册的处理器在具名模式下不生效
+func (c *Context) Resolve(statement *Statement) (string, []any, error) {
+	if c.named {
+		if len(statement.Bindings) != 0 {
+			return "", nil, errors.UnsupportedNamed("derived parameters")
+		}
+		for _, opts := range statement.Args() {
+			if _, exist, err := c.typeHandlers().Lookup(opts.TypeHandler(), opts.Name, nil); err != nil || exist {
+				return "", nil, errors.UnsupportedNamed("type handlers (" + opts.Name + ")")
+			}
+			if opts.Default != nil {
+				return "", nil, errors.UnsupportedNamed("default values (" + opts.Name + ")")
+			}
+		}
+		return statement.Stmt, nil, nil
+	}
+
+	args := make([]any, 0, len(statement.ArgNames))
+	var expand map[int]int // 占位符序号 -> 展开后的个数
+	for idx, opts := range statement.Args() {
+		value, exist := c.lookupParam(opts, statement.Bindings)
+		if !exist {
+			return "", nil, errors.MissingParameter(opts.Name)
+		}
+		value, err := c.typeHandlers().ToDB(opts.TypeHandler(), opts.Name, value)
+		if err != nil {
+			return "", nil, err
+		}
+		if values, ok := expandable(value); ok && opts.Expandable() {
+			if expand == nil {
+				expand = map[int]int{}
+			}
+			expand[idx] = len(values)
+			args = append(args, values...)
+			continue
+		}
+		args = append(args, value)
+	}
+
+	if expand == nil && !numbered(c.dialect) {
+		return statement.Stmt, args, nil
+	}
+	return bindPlaceholders(statement.Stmt, expand, c.dialect), args, nil
+}
+
+// expandable 判断参数值是否需要展开，[]byte 作为单个值处理
+func expandable(value any) ([]any, bool) {
+	if value == nil {
+		return nil, false
+	}
+	if _, ok := value.([]byte); ok {
+		return nil, false
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	values := make([]any, rv.Len())
+	for i := range values {
+		values[i] = rv.Index(i).Interface()
+	}
+	return values, true
+}
+
+// numbered 使用带序号占位符的方言
+func numbered(dialect Dialect) bool {
+	return dialect == PostgreSQL || dialect == SQLServer || dialect == Oracle
+}
+
+// placeholder 方言对应的第n个（从1开始）占位符
+func placeholder(dialect Dialect, n int) string {
+	switch dialect {
+	case PostgreSQL:
+		return "$" + strconv.Itoa(n)
+	case SQLServer:
+		return "@p" + strconv.Itoa(n)
+	case Oracle:
+		return ":" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// bindPlaceholders 将第idx个 ? 占位符展开为expand[idx]个以逗号分隔的占位符（为0时替换为NULL），
+// 并按方言输出占位符
+func bindPlaceholders(stmt string, expand map[int]int, dialect Dialect) string {
+	var b strings.Builder
+	idx, n := 0, 0
+	write := func() {
+		n++
+		b.WriteString(placeholder(dialect, n))
+	}
+	for _, t := range tokenize(stmt) {
+		if t.is(tokPunct, "??") && numbered(dialect) {
+			b.WriteString("?")
+			continue
+		}
+		if t.kind != tokPlaceholder || t.text != "?" {
+			b.WriteString(t.text)
+			continue
+		}
+		count, exist := expand[idx]
+		idx++
+		switch {
+		case !exist:
+			write()
+		case count == 0:
+			b.WriteString("NULL")
+		default:
+			for i := 0; i < count; i++ {
+				if i != 0 {
+					b.WriteString(", ")
+				}
+				write()
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,151 @@
+package sql
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Properties 全局配置属性，如各环境不同的库名、表前缀，
+// 片段中的 ${}、include 的 $ 属性及属性指定的 refid 在调用参数中找不到时依次查找 Context 及 sql定义来源（Registry、PropertyCollection）上的配置属性
+type Properties map[string]string
+
+// Get 实现属性查找
+func (p Properties) Get(key string) (string, bool) {
+	v, exist := p[key]
+	return v, exist
+}
+
+// Merge 合并多个配置属性，靠后的覆盖靠前的，不修改原有属性
+func (p Properties) Merge(others ...Properties) Properties {
+	res := Properties{}
+	for _, props := range append([]Properties{p}, others...) {
+		for k, v := range props {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+// Keys 按字典序返回所有属性名
+func (p Properties) Keys() []string {
+	keys := make([]string, 0, len(p))
+	for k := range p {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// LoadProperties 读取 .properties 格式的配置：每行 key=value 或 key: value，# 或 ! 开头为注释
+func LoadProperties(r io.Reader) (Properties, error) {
+	props := Properties{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || text[0] == '#' || text[0] == '!' {
+			continue
+		}
+		idx := strings.IndexAny(text, "=:")
+		if idx <= 0 {
+			return nil, fmt.Errorf("invalid property at line %d: %s", line, text)
+		}
+		props[strings.TrimSpace(text[:idx])] = strings.TrimSpace(text[idx+1:])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return props, nil
+}
+
+// LoadYAMLProperties 读取yaml格式的配置，嵌套的键以 . 连接，如 db.schema，值只能是标量
+func LoadYAMLProperties(r io.Reader) (Properties, error) {
+	var doc map[string]any
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil && err != io.EOF {
+		return nil, err
+	}
+	props := Properties{}
+	if err := flatten(props, "", doc); err != nil {
+		return nil, err
+	}
+	return props, nil
+}
+
+func flatten(props Properties, prefix string, v any) error {
+	switch value := v.(type) {
+	case map[string]any:
+		for k, child := range value {
+			if err := flatten(props, joinKey(prefix, k), child); err != nil {
+				return err
+			}
+		}
+	case nil:
+		props[prefix] = ""
+	case string, bool, int, int64, uint64, float64, time.Time:
+		props[prefix] = String(value)
+	default:
+		return fmt.Errorf("invalid property %s: non-scalar value %T", prefix, value)
+	}
+	return nil
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// EnvProperties 读取以prefix开头的环境变量，去掉前缀后作为属性名，如 prefix 为 APP_ 时 APP_SCHEMA -> SCHEMA
+func EnvProperties(prefix string) Properties {
+	props := Properties{}
+	for _, env := range os.Environ() {
+		k, v, _ := strings.Cut(env, "=")
+		if name, ok := strings.CutPrefix(k, prefix); ok && name != "" {
+			props[name] = v
+		}
+	}
+	return props
+}
+
+// propertySource 带有全局配置属性的sql定义来源
+type propertySource interface {
+	Properties() Properties
+}
+
+// PropertyCollection 带全局配置属性的sql定义集合，通过 Context.WithDefinitions 使用
+type PropertyCollection struct {
+	Collection
+	Props Properties
+}
+
+// WithProperties 为集合附加全局配置属性
+func (c Collection) WithProperties(properties Properties) *PropertyCollection {
+	return &PropertyCollection{Collection: c, Props: properties}
+}
+
+func (c *PropertyCollection) Properties() Properties {
+	return c.Props
+}
+
+// Parameters 同 Collection.Parameters，由配置属性满足的 ${} 不计入
+func (c *PropertyCollection) Parameters(id string) (*ParameterSet, error) {
+	return parameters(c, id)
+}
+
+// property 查找配置属性，Context 上的配置优先于sql定义来源上的配置
+func (c *Context) property(name string) (string, bool) {
+	if v, exist := c.properties[name]; exist {
+		return v, true
+	}
+	if source, ok := c.collection.(propertySource); ok {
+		return source.Properties().Get(name)
+	}
+	return "", false
+}
@@ -0,0 +1,121 @@
+package sql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProperties(t *testing.T) {
+	props, err := LoadProperties(strings.NewReader("# comment\nschema = prod\ntablePrefix: gc_\n"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, Properties{"schema": "prod", "tablePrefix": "gc_"}, props)
+	}
+	_, err = LoadProperties(strings.NewReader("schema"))
+	assert.Error(t, err)
+
+	yamlProps, err := LoadYAMLProperties(strings.NewReader("db:\n  schema: test\n  shards: 4\n"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, Properties{"db.schema": "test", "db.shards": "4"}, yamlProps)
+	}
+
+	// 列表等非标量值不能作为属性
+	_, err = LoadYAMLProperties(strings.NewReader("db:\n  shards: [a, b]\n"))
+	assert.EqualError(t, err, "invalid property db.shards: non-scalar value []interface {}")
+
+	t.Setenv("GOBATIS_TEST_SCHEMA", "env")
+	assert.Equal(t, Properties{"SCHEMA": "env"}, EnvProperties("GOBATIS_TEST_"))
+
+	e := Frag(`SELECT * FROM ${schema}.${tablePrefix}image WHERE id = #{id}`)
+
+	// Context 上的配置属性
+	stmt, err := e.Evaluate(NewContext().
+		WithProperties(props).
+		WithParams(MapParameters{"id": 1}))
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM prod.gc_image WHERE id = ?`, stmt.GetStmt())
+	}
+
+	// 调用参数优先，Context 配置优先于注册表配置
+	r := NewRegistry()
+	r.MustRegister("Image", Collection{"Select": e})
+	r.SetProperties(Properties{"schema": "registry", "tablePrefix": "t_"})
+	stmt2, _, err := PrepareID(NewContext().
+		WithRegistry(r).
+		WithProperties(Properties{"schema": "context"}).
+		WithParams(MapParameters{"id": 1, "tablePrefix": "p_"}), "Image.Select").Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM context.p_image WHERE id = ?`, stmt2)
+	}
+	stmt3, _, err := PrepareID(NewContext().
+		WithRegistry(r).
+		WithParams(MapParameters{"id": 1}), "Image.Select").Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM registry.t_image WHERE id = ?`, stmt3)
+	}
+}
+
+func TestPropertyCollection(t *testing.T) {
+	c := Collection{
+		"Image.Select": Frag(`SELECT * FROM ${schema}.${tablePrefix}image WHERE id = #{id}`),
+	}
+
+	// 集合上的配置属性
+	defs := c.WithProperties(Properties{"schema": "prod"})
+	stmt, _, err := PrepareID(NewContext().
+		WithDefinitions(defs).
+		WithParams(MapParameters{"id": 1, "tablePrefix": "gc_"}), "Image.Select").Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM prod.gc_image WHERE id = ?`, stmt)
+	}
+
+	// 由配置属性满足的 ${} 不再视为必需参数
+	set, err := c.Parameters("Image.Select")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"schema", "tablePrefix"}, set.Props())
+	}
+	set, err = defs.Parameters("Image.Select")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"tablePrefix"}, set.Props())
+	}
+
+	r := NewRegistry()
+	r.MustRegister("Image", Collection{"Select": c["Image.Select"]})
+	r.SetProperties(Properties{"schema": "registry", "tablePrefix": "t_"})
+	set, err = r.Parameters("Image.Select")
+	if assert.NoError(t, err) {
+		assert.Empty(t, set.Props())
+	}
+}
+
+func TestIncludeProperties(t *testing.T) {
+	c := Collection{
+		"Fields":    Frag(`${alias}.id`),
+		"Select":    Composite(`SELECT`, Include("Fields", false, MapParameters{"alias": "$tableAlias"}), `FROM t`),
+		"Dynamic":   Include("fieldsRef", true, MapParameters{"alias": "t"}),
+		"Undefined": Include("Fields", false, MapParameters{"alias": "$missing"}),
+	}
+	defs := c.WithProperties(Properties{"tableAlias": "t", "fieldsRef": "Fields"})
+
+	// 清单中不要求传入的属性，运行时同样从配置属性取值
+	for id, expected := range map[string]string{"Select": `SELECT t.id FROM t`, "Dynamic": `t.id`} {
+		set, err := defs.Parameters(id)
+		if assert.NoError(t, err) {
+			assert.Empty(t, set.Props(), id)
+		}
+		stmt, _, err := PrepareID(NewContext().WithDefinitions(defs).WithFormat(FormatCompact), id).Prepare()
+		if assert.NoError(t, err, id) {
+			assert.Equal(t, expected, stmt)
+		}
+	}
+
+	// 调用参数优先
+	stmt, _, err := PrepareID(NewContext().WithDefinitions(defs).WithParams(MapParameters{"tableAlias": "p"}).WithFormat(FormatCompact), "Select").Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT p.id FROM t`, stmt)
+	}
+
+	_, _, err = PrepareID(NewContext().WithDefinitions(defs), "Undefined").Prepare()
+	assert.EqualError(t, err, "missing parameter: missing")
+}
@@ -0,0 +1,99 @@
+package sql
+
+import (
+	"sync/atomic"
+
+	"github.com/non1996/go-batis/errors"
+)
+
+// Registry 并发安全、支持命名空间的sql定义注册表，定义保存在 cowMap 中，
+// 适合在init阶段由多个模块注册、运行期只读的场景
+type Registry struct {
+	defs       cowMap[string, SQL]
+	properties atomic.Pointer[Properties]
+}
+
+func NewRegistry() *Registry {
+	r := &Registry{}
+	r.properties.Store(&Properties{})
+	return r
+}
+
+// SetProperties 设置注册表级别的全局配置属性，整体原子替换
+func (r *Registry) SetProperties(properties Properties) {
+	properties = Properties{}.Merge(properties)
+	r.properties.Store(&properties)
+}
+
+// Properties 返回注册表级别的全局配置属性，调用方不可修改
+func (r *Registry) Properties() Properties {
+	return *r.properties.Load()
+}
+
+// Register 在命名空间下注册sql定义，id为相对id，与已有定义冲突时不做任何修改并返回错误
+func (r *Registry) Register(namespace string, defs Collection) error {
+	return r.defs.update(func(current, next map[string]SQL) error {
+		for id := range defs {
+			if _, exist := current[qualifyID(namespace, id)]; exist {
+				return errors.DuplicateSQL(qualifyID(namespace, id))
+			}
+		}
+		for id, sql := range defs {
+			next[qualifyID(namespace, id)] = sql
+		}
+		return nil
+	})
+}
+
+// MustRegister 同 Register，冲突时panic，用于init阶段
+func (r *Registry) MustRegister(namespace string, defs Collection) {
+	if err := r.Register(namespace, defs); err != nil {
+		panic(err)
+	}
+}
+
+// Override 在命名空间下注册sql定义，覆盖已有定义，返回被覆盖的完整id
+func (r *Registry) Override(namespace string, defs Collection) (overridden []string) {
+	_ = r.defs.update(func(current, next map[string]SQL) error {
+		for id, sql := range defs {
+			fullID := qualifyID(namespace, id)
+			if _, exist := current[fullID]; exist {
+				overridden = append(overridden, fullID)
+			}
+			next[fullID] = sql
+		}
+		return nil
+	})
+	return overridden
+}
+
+// Replace 以新的定义集合整体原子替换注册表内容，collection中的id需为完整id
+func (r *Registry) Replace(collection Collection) {
+	next := make(map[string]SQL, len(collection))
+	for id, sql := range collection {
+		next[id] = sql
+	}
+	r.defs.replace(next)
+}
+
+// Lookup 按完整id查找sql定义，无锁
+func (r *Registry) Lookup(id string) (SQL, bool) {
+	return r.defs.get(id)
+}
+
+// Snapshot 返回当前定义集合的快照，调用方不可修改
+func (r *Registry) Snapshot() Collection {
+	return r.defs.load()
+}
+
+// Parameters 静态分析sql定义可引用的参数，见 Collection.Parameters
+func (r *Registry) Parameters(id string) (*ParameterSet, error) {
+	return parameters(r, id)
+}
+
+func qualifyID(namespace, id string) string {
+	if namespace == "" {
+		return id
+	}
+	return namespace + "." + id
+}
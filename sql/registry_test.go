@@ -0,0 +1,58 @@
+package sql
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	r.MustRegister("Image", Collection{
+		"Fields": Frag(`${alias}.id, ${alias}.md5`),
+		"Select": Composite(
+			`SELECT`,
+			Include("Fields", false, MapParameters{"alias": "gi"}),
+			`FROM gc_image gi`,
+		),
+	})
+	r.MustRegister("Bundle", Collection{
+		"Fields": Frag(`${alias}.id, ${alias}.path`),
+	})
+
+	// 相对引用解析为所在命名空间下的定义
+	stmt, _, err := PrepareID(NewContext().WithRegistry(r), "Image.Select").Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT gi.id, gi.md5 FROM gc_image gi`, stmt)
+	}
+	set, err := r.Parameters("Image.Select")
+	if assert.NoError(t, err) {
+		assert.Empty(t, set.Props())
+	}
+
+	// 冲突检测
+	err = r.Register("Image", Collection{"Fields": Frag(`*`)})
+	assert.EqualError(t, err, "duplicate sql: Image.Fields")
+	assert.Equal(t, []string{"Bundle.Fields"}, r.Override("Bundle", Collection{"Fields": Frag(`*`)}))
+
+	// 并发注册与查找
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r.MustRegister(fmt.Sprintf("M%d", i), Collection{"Q": Frag(`SELECT 1`)})
+			_, exist := r.Lookup("Image.Select")
+			assert.True(t, exist)
+		}(i)
+	}
+	wg.Wait()
+	assert.Len(t, r.Snapshot(), 19)
+
+	// 原子替换
+	r.Replace(Collection{"Only": Frag(`SELECT 1`)})
+	_, exist := r.Lookup("Image.Select")
+	assert.False(t, exist)
+}
@@ -0,0 +1,129 @@
+package sql
+
+import (
+	"fmt"
+
+	"github.com/non1996/go-batis/errors"
+)
+
+// ScriptFunc 以go代码生成sql片段，可通过 Context.Lookup 读取参数，通过 Context.Bind 绑定参数值
+type ScriptFunc func(ctx *Context) (*Statement, error)
+
+// Script 由go代码生成的sql片段，可作为 Trim/Where/Set 的子元素，返回nil时不输出
+//
+//	sql.Where(sql.Script(func(ctx *sql.Context) (*sql.Statement, error) {
+//		statement := &sql.Statement{}
+//		ctx.Bind(statement, tenantOf(ctx.Context()))
+//		statement.Stmt = "AND tenant_id = ?"
+//		return statement, nil
+//	}))
+func Script(fn ScriptFunc) ConditionElem {
+	return &script{fn: fn}
+}
+
+// ScriptRef 引用注册的具名脚本，求值时按名查找，mapper中写作 <script name="..."/>
+func ScriptRef(name string) ConditionElem {
+	return &script{name: name}
+}
+
+// Scripts 并发安全的具名脚本注册表
+type Scripts struct {
+	scripts cowMap[string, ScriptFunc]
+}
+
+// DefaultScripts Context 未设置脚本注册表时使用的注册表
+var DefaultScripts = NewScripts()
+
+func NewScripts() *Scripts {
+	return &Scripts{}
+}
+
+// Register 注册具名脚本，同名时覆盖
+func (s *Scripts) Register(name string, fn ScriptFunc) {
+	s.scripts.set(name, fn)
+}
+
+// Lookup 按名查找脚本
+func (s *Scripts) Lookup(name string) (ScriptFunc, bool) {
+	return s.scripts.get(name)
+}
+
+// RegisterScript 在 DefaultScripts 中注册具名脚本
+func RegisterScript(name string, fn ScriptFunc) {
+	DefaultScripts.Register(name, fn)
+}
+
+// WithScripts 设置具名脚本注册表，未设置时使用 DefaultScripts
+func (c *Context) WithScripts(scripts *Scripts) *Context {
+	c.scripts = scripts
+	return c
+}
+
+// Bind 为脚本生成的参数值生成参数名，并在语句中追加对应的参数，语句中以 ? 引用
+func (c *Context) Bind(statement *Statement, value any) string {
+	return c.bind(statement, value)
+}
+
+func (c *Context) lookupScript(name string) (ScriptFunc, bool) {
+	scripts := c.scripts
+	if scripts == nil {
+		scripts = DefaultScripts
+	}
+	return scripts.Lookup(name)
+}
+
+// script go代码生成的sql片段，name 不为空时为对具名脚本的引用
+type script struct {
+	fn   ScriptFunc
+	name string
+}
+
+func (s *script) Satisfy(ctx *Context) (bool, error) {
+	return true, nil
+}
+
+func (s *script) String() string {
+	if s.name != "" {
+		return "script " + s.name
+	}
+	return "script"
+}
+
+func (s *script) Evaluate(ctx *Context) (statement *Statement, err error) {
+	node, err := ctx.enter(s)
+	defer func() { ctx.leave(node, statement, err) }()
+	if err != nil {
+		return nil, err
+	}
+
+	fn := s.fn
+	if s.name != "" {
+		var exist bool
+		if fn, exist = ctx.lookupScript(s.name); !exist {
+			return nil, errors.MissingScript(s.name)
+		}
+	}
+	statement, err = fn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if statement == nil {
+		return emptyStatement, nil
+	}
+	return statement, nil
+}
+
+// inspect 脚本的输出只有求值时才能确定，记为一个无法静态分析的条件分组，使引用它的语句不被视为静态语句
+func (s *script) inspect(w *walker) error {
+	return w.guarded(guards(s), func() error {
+		w.group()
+		return nil
+	})
+}
+
+func (s *script) goSource() (string, error) {
+	if s.name == "" {
+		return "", errors.UnsupportedElement("script func")
+	}
+	return fmt.Sprintf("sql.ScriptRef(%q)", s.name), nil
+}
@@ -0,0 +1,72 @@
+package sql
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tenantKey struct{}
+
+func tenantScript(ctx *Context) (*Statement, error) {
+	tenant, _ := ctx.Context().Value(tenantKey{}).(int)
+	if tenant == 0 {
+		return nil, nil
+	}
+	statement := &Statement{Stmt: "AND tenant_id = ?"}
+	ctx.Bind(statement, tenant)
+	return statement, nil
+}
+
+func TestScript(t *testing.T) {
+	e := Composite(`SELECT * FROM gc_image`, Where(Script(tenantScript), If(Test(".md5"), `AND md5 = #{md5}`)))
+	goCtx := context.WithValue(context.Background(), tenantKey{}, 7)
+
+	stmt, args, err := Prepare(NewContext().WithContext(goCtx).WithParams(MapParameters{"md5": "abc"}).WithDialect(PostgreSQL), e).Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM gc_image WHERE tenant_id = $1 AND md5 = $2`, stmt)
+		assert.Equal(t, []any{7, "abc"}, args)
+	}
+
+	// 返回nil时不输出
+	stmt, _, err = Prepare(NewContext().WithParams(MapParameters{"md5": ""}).WithFormat(FormatCompact), e).Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SELECT * FROM gc_image`, stmt)
+	}
+
+	_, err = Script(tenantScript).(interface{ goSource() (string, error) }).goSource()
+	assert.Error(t, err)
+}
+
+func TestScriptRef(t *testing.T) {
+	scripts := NewScripts()
+	scripts.Register("tenant", tenantScript)
+
+	m, err := ParseMapper(strings.NewReader(`<mapper namespace="Image">
+  <update id="Touch">UPDATE gc_image SET updated_at = NOW() <where><script name="tenant"/> AND id = #{id}</where></update>
+</mapper>`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	collection, err := Mappers{m}.Collection()
+	if !assert.NoError(t, err) {
+		return
+	}
+	e := collection["Image.Touch"]
+	goCtx := context.WithValue(context.Background(), tenantKey{}, 7)
+
+	stmt, args, err := Prepare(NewContext().WithContext(goCtx).WithScripts(scripts).WithParams(MapParameters{"id": 1}), e).Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `UPDATE gc_image SET updated_at = NOW() WHERE tenant_id = ? AND id = ?`, stmt)
+		assert.Equal(t, []any{7, 1}, args)
+	}
+
+	// 未注册的脚本
+	_, _, err = Prepare(NewContext().WithContext(goCtx).WithParams(MapParameters{"id": 1}), e).Prepare()
+	assert.EqualError(t, err, "missing script: tenant")
+
+	_, err = ParseMapper(strings.NewReader(`<mapper><select id="x"><script/></select></mapper>`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,142 @@
+package sql
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/non1996/go-jsonobj/stream"
+
+	"github.com/non1996/go-batis/errors"
+)
+
+// sourcer 能输出构造自身的go代码的元素
+type sourcer interface {
+	goSource() (string, error)
+}
+
+// GoSource 输出构造sql元素的go代码，用于代码生成，代码中以 sql. 引用本包
+func GoSource(e Elem) (string, error) {
+	if s, ok := e.(sourcer); ok {
+		return s.goSource()
+	}
+	return "", errors.UnsupportedElement(fmt.Sprintf("%T", e))
+}
+
+func goSourceAll(elems []Elem) (string, error) {
+	var b strings.Builder
+	for _, e := range elems {
+		src, err := GoSource(e)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString("\n" + src + ",")
+	}
+	if b.Len() != 0 {
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+func goCondition(c Condition) (string, error) {
+	switch cond := c.(type) {
+	case *trueCondition:
+		return "sql.True()", nil
+	case *templateCondition:
+		return fmt.Sprintf("sql.Test(%s)", goString(cond.cond)), nil
+	case *dialectCondition:
+		dialects := make([]string, 0, len(cond.dialects))
+		for _, d := range cond.dialects {
+			dialects = append(dialects, strconv.Quote(string(d)))
+		}
+		return fmt.Sprintf("sql.DialectIs(%s)", strings.Join(dialects, ", ")), nil
+	}
+	return "", errors.UnsupportedElement(fmt.Sprintf("%T", c))
+}
+
+func goStrings(ss []string) string {
+	if ss == nil {
+		return "nil"
+	}
+	quoted := make([]string, 0, len(ss))
+	for _, s := range ss {
+		quoted = append(quoted, goString(s))
+	}
+	return "[]string{" + strings.Join(quoted, ", ") + "}"
+}
+
+// goString 字符串字面量，多行且不含反引号时使用原始字符串
+func goString(s string) string {
+	if strings.Contains(s, "\n") && !strings.Contains(s, "`") {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+func goValue(v any) string {
+	if s, ok := v.(string); ok {
+		return goString(s)
+	}
+	return fmt.Sprintf("%#v", v)
+}
+
+func (s *pure) goSource() (string, error) {
+	return fmt.Sprintf("sql.Frag(%s)", goString(s.Stmt)), nil
+}
+
+func (s *fragment) goSource() (string, error) {
+	return fmt.Sprintf("sql.Frag(%s)", goString(s.raw)), nil
+}
+
+func (s *_include) goSource() (string, error) {
+	keys := s.Props.Keys()
+	sort.Strings(keys)
+	props := make([]string, 0, len(keys))
+	for _, k := range keys {
+		props = append(props, fmt.Sprintf("%s: %s", strconv.Quote(k), goValue(s.Props.Get(k))))
+	}
+	return fmt.Sprintf("sql.Include(%s, %t, sql.MapParameters{%s})",
+		goString(s.ID), s.IDFromProp, strings.Join(props, ", ")), nil
+}
+
+func (s *_if) goSource() (string, error) {
+	cond, err := goCondition(s.Condition)
+	if err != nil {
+		return "", err
+	}
+	children, err := goSourceAll(s.Children)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sql.If(%s,%s)", cond, children), nil
+}
+
+func (s *choose) goSource() (string, error) {
+	children, err := goSourceAll(elems(s.Children))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sql.Choose(%s)", children), nil
+}
+
+func (s *trim) goSource() (string, error) {
+	children, err := goSourceAll(elems(s.Children))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sql.Trim(%s, %s, %s,%s)",
+		goString(s.Prefix), goStrings(s.PrefixOverrides), goStrings(s.SuffixOverrides), children), nil
+}
+
+func (s *composite) goSource() (string, error) {
+	children, err := goSourceAll(s.Children)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sql.Composite(%s)", children), nil
+}
+
+func elems(children []ConditionElem) []Elem {
+	return stream.Map(children, func(e ConditionElem) Elem { return e })
+}
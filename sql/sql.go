@@ -28,6 +28,7 @@ type SQL interface {
 
 func Frag(stmt string) Elem {
 	var (
+		raw    = stmt
 		props  []string
 		params []string
 	)
@@ -40,6 +41,7 @@ func Frag(stmt string) Elem {
 	}
 
 	return &fragment{
+		raw:        raw,
 		stmt:       stmt,
 		properties: props,
 		parameters: params,
@@ -127,17 +129,30 @@ type pure struct {
 }
 
 func (s *pure) Evaluate(ctx *Context) (statement *Statement, err error) {
+	node, err := ctx.enter(s)
+	defer func() { ctx.leave(node, statement, err) }()
+	if err != nil {
+		return nil, err
+	}
+
 	return NewStatement(s.Stmt, nil), nil
 }
 
 // fragment 带参数或属性的sql片段
 type fragment struct {
+	raw        string // 原始片段，用于生成代码等场景
 	stmt       string
 	properties []string
 	parameters []string
 }
 
 func (s *fragment) Evaluate(ctx *Context) (statement *Statement, err error) {
+	node, err := ctx.enter(s)
+	defer func() { ctx.leave(node, statement, err) }()
+	if err != nil {
+		return nil, err
+	}
+
 	var stmt string
 	var args []string
 
@@ -156,25 +171,35 @@ func (s *fragment) Evaluate(ctx *Context) (statement *Statement, err error) {
 
 func (s *fragment) evaluateProps(ctx *Context, stmt string) (string, error) {
 	for idx, prop := range s.properties {
-		exist := ctx.params.Exist(prop)
-		if !exist {
-			return "", errors.MissingParameter(prop)
+		value, err := propertyValue(ctx, prop)
+		if err != nil {
+			return "", err
 		}
-		value := ctx.params.Get(prop)
-		stmt = strings.ReplaceAll(stmt, fmt.Sprintf("${%d}", idx), String(value))
+		stmt = strings.ReplaceAll(stmt, fmt.Sprintf("${%d}", idx), value)
 	}
 
 	return stmt, nil
 }
 
+// propertyValue 属性值，调用参数中不存在时使用全局配置属性
+func propertyValue(ctx *Context, prop string) (string, error) {
+	if value, exist := ctx.Lookup(prop); exist {
+		return String(value), nil
+	}
+	if value, exist := ctx.property(prop); exist {
+		return value, nil
+	}
+	return "", errors.MissingParameter(prop)
+}
+
 func (s *fragment) evaluateParams(ctx *Context, stmt string) (string, []string, error) {
 	for idx, param := range s.parameters {
+		opts := ParseParam(param)
 		if ctx.named {
-			stmt = strings.Replace(stmt, fmt.Sprintf("${%d}", idx), ":"+param, 1)
+			stmt = strings.Replace(stmt, fmt.Sprintf("#{%d}", idx), ":"+opts.Name, 1)
 		} else {
-			exist := ctx.params.Exist(param)
-			if !exist {
-				return "", nil, errors.MissingParameter(param)
+			if _, exist := ctx.lookupParam(opts, nil); !exist {
+				return "", nil, errors.MissingParameter(opts.Name)
 			}
 			stmt = strings.Replace(stmt, fmt.Sprintf("#{%d}", idx), "?", 1)
 		}
@@ -195,10 +220,7 @@ func (s *_include) prepareID(ctx *Context) (id string, err error) {
 		return s.ID, nil
 	}
 
-	if !ctx.params.Exist(s.ID) {
-		return "", errors.MissingParameter(s.ID)
-	}
-	return String(ctx.params.Get(s.ID)), nil
+	return propertyValue(ctx, s.ID)
 }
 
 func (s *_include) prepareProps(ctx *Context) (Parameters, error) {
@@ -208,10 +230,15 @@ func (s *_include) prepareProps(ctx *Context) (Parameters, error) {
 		vs := String(s.Props.Get(k))
 		if vs[0] == '$' {
 			vs = vs[1:]
-			if !ctx.params.Exist(vs) {
-				return nil, errors.MissingParameter(vs)
+			value, exist := ctx.Lookup(vs)
+			if !exist {
+				prop, ok := ctx.property(vs)
+				if !ok {
+					return nil, errors.MissingParameter(vs)
+				}
+				value = prop
 			}
-			props[k] = ctx.params.Get(vs)
+			props[k] = value
 		} else {
 			props[k] = s.Props.Get(k)
 		}
@@ -221,6 +248,12 @@ func (s *_include) prepareProps(ctx *Context) (Parameters, error) {
 }
 
 func (s *_include) Evaluate(ctx *Context) (statement *Statement, err error) {
+	node, err := ctx.enter(s)
+	defer func() { ctx.leave(node, statement, err) }()
+	if err != nil {
+		return nil, err
+	}
+
 	id, err := s.prepareID(ctx)
 	if err != nil {
 		return nil, err
@@ -229,9 +262,13 @@ func (s *_include) Evaluate(ctx *Context) (statement *Statement, err error) {
 	if err != nil {
 		return nil, err
 	}
+	node.include(id, props)
 
-	refed := ctx.GetSQL(id)
-	return refed.Evaluate(ctx.Next(props))
+	fullID, refed, err := ctx.resolve(id)
+	if err != nil {
+		return nil, err
+	}
+	return refed.Evaluate(ctx.Next(props).WithNamespace(namespaceOf(fullID)))
 }
 
 // _if 动态sql中的if标签，根据参数判断是否添加sql片段
@@ -241,10 +278,17 @@ type _if struct {
 }
 
 func (s *_if) Evaluate(ctx *Context) (statement *Statement, err error) {
+	node, err := ctx.enter(s)
+	defer func() { ctx.leave(node, statement, err) }()
+	if err != nil {
+		return nil, err
+	}
+
 	satisfy, err := s.Satisfy(ctx)
 	if err != nil {
 		return nil, err
 	}
+	node.condition(s.Condition, satisfy)
 	if !satisfy {
 		return emptyStatement, nil
 	}
@@ -264,6 +308,12 @@ type choose struct {
 }
 
 func (s *choose) Evaluate(ctx *Context) (statement *Statement, err error) {
+	node, err := ctx.enter(s)
+	defer func() { ctx.leave(node, statement, err) }()
+	if err != nil {
+		return nil, err
+	}
+
 	for _, child := range s.Children {
 		satisfy, err := child.Satisfy(ctx)
 		if err != nil {
@@ -272,6 +322,7 @@ func (s *choose) Evaluate(ctx *Context) (statement *Statement, err error) {
 		if satisfy {
 			return child.Evaluate(ctx)
 		}
+		ctx.skip(child)
 	}
 	return emptyStatement, nil
 }
@@ -306,6 +357,12 @@ func (s *trim) trimSuffix(statement *Statement) {
 }
 
 func (s *trim) Evaluate(ctx *Context) (statement *Statement, err error) {
+	node, err := ctx.enter(s)
+	defer func() { ctx.leave(node, statement, err) }()
+	if err != nil {
+		return nil, err
+	}
+
 	var childStatements []*Statement
 	for _, child := range s.Children {
 		satisfy, err := child.Satisfy(ctx)
@@ -313,6 +370,7 @@ func (s *trim) Evaluate(ctx *Context) (statement *Statement, err error) {
 			return nil, err
 		}
 		if !satisfy {
+			ctx.skip(child)
 			continue
 		}
 
@@ -320,6 +378,10 @@ func (s *trim) Evaluate(ctx *Context) (statement *Statement, err error) {
 		if err != nil {
 			return nil, err
 		}
+		// 子元素求值后才能确定是否为空，如脚本，空语句不参与前后缀处理
+		if strings.TrimSpace(childStatement.Stmt) == "" {
+			continue
+		}
 		childStatements = append(childStatements, childStatement)
 	}
 
@@ -338,6 +400,12 @@ type composite struct {
 }
 
 func (s *composite) Evaluate(ctx *Context) (statement *Statement, err error) {
+	node, err := ctx.enter(s)
+	defer func() { ctx.leave(node, statement, err) }()
+	if err != nil {
+		return nil, err
+	}
+
 	childStatements, err := stream.MapWithError(s.Children, func(e Elem) (*Statement, error) {
 		return e.Evaluate(ctx)
 	})
@@ -8,7 +8,8 @@ var emptyStatement = &Statement{}
 
 type Statement struct {
 	Stmt     string
-	ArgNames []string
+	ArgNames []string      // 各占位符的参数，可带选项，见 ParamOptions
+	Bindings MapParameters // 元素推导出的参数值，如分页偏移量，解析参数时优先于上下文参数
 }
 
 func NewStatement(
@@ -33,6 +34,15 @@ func (s Statement) GetArgNames() []string {
 	return s.ArgNames
 }
 
+// Args 各占位符的参数名及选项
+func (s Statement) Args() []ParamOptions {
+	args := make([]ParamOptions, 0, len(s.ArgNames))
+	for _, spec := range s.ArgNames {
+		args = append(args, ParseParam(spec))
+	}
+	return args
+}
+
 func StatementMerge(statements []*Statement, prefix ...string) *Statement {
 	var stmts = make([]string, 0, len(statements)+len(prefix))
 	var args = make([]string, 0, len(statements))
@@ -41,13 +51,21 @@ func StatementMerge(statements []*Statement, prefix ...string) *Statement {
 		stmts = append(stmts, prefix[0])
 	}
 
+	var bindings MapParameters
 	for _, s := range statements {
 		stmts = append(stmts, s.Stmt)
 		args = append(args, s.ArgNames...)
+		for k, v := range s.Bindings {
+			if bindings == nil {
+				bindings = MapParameters{}
+			}
+			bindings[k] = v
+		}
 	}
 
 	return &Statement{
 		Stmt:     strings.Join(stmts, " "),
 		ArgNames: args,
+		Bindings: bindings,
 	}
 }
@@ -0,0 +1,184 @@
+package sql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// TraceNode 一次Evaluate过程中某个元素的求值记录
+type TraceNode struct {
+	Kind      string        `json:"kind"`                // 元素类型，如 frag/include/if/choose/trim/composite
+	Condition string        `json:"condition,omitempty"` // 条件文本，仅 if/when/otherwise
+	Result    *bool         `json:"result,omitempty"`    // 条件求值结果
+	IncludeID string        `json:"includeId,omitempty"` // 被引用的sql id，仅 include
+	Props     MapParameters `json:"props,omitempty"`     // 传递给 Context.Next 的属性，仅 include
+	Stmt      string        `json:"stmt"`                // 该元素生成的sql
+	ArgNames  []string      `json:"argNames,omitempty"`  // 该元素生成的参数
+	Error     string        `json:"error,omitempty"`
+	Children  []*TraceNode  `json:"children,omitempty"`
+}
+
+// Trace 动态sql的求值追踪树
+type Trace struct {
+	Roots []*TraceNode `json:"roots"`
+}
+
+// String 以缩进文本的形式输出追踪树
+func (t *Trace) String() string {
+	var b strings.Builder
+	for _, root := range t.Roots {
+		root.write(&b, 0)
+	}
+	return b.String()
+}
+
+// JSON 以json的形式输出追踪树
+func (t *Trace) JSON() ([]byte, error) {
+	return json.MarshalIndent(t, "", "  ")
+}
+
+func (n *TraceNode) write(b *strings.Builder, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString(n.Kind)
+	if n.IncludeID != "" {
+		fmt.Fprintf(b, " %s", n.IncludeID)
+		if len(n.Props) != 0 {
+			fmt.Fprintf(b, " %v", map[string]any(n.Props))
+		}
+	}
+	if n.Result != nil {
+		fmt.Fprintf(b, " [%s] => %t", n.Condition, *n.Result)
+	}
+	if n.Error != "" {
+		fmt.Fprintf(b, " !! %s", n.Error)
+	} else if n.Stmt != "" {
+		fmt.Fprintf(b, ": %s", n.Stmt)
+		if len(n.ArgNames) != 0 {
+			fmt.Fprintf(b, " %v", n.ArgNames)
+		}
+	}
+	b.WriteByte('\n')
+	for _, child := range n.Children {
+		child.write(b, depth+1)
+	}
+}
+
+// tracer 记录求值过程，被同一次求值中的所有 Context 共享
+type tracer struct {
+	trace Trace
+	stack []*TraceNode
+}
+
+func (t *tracer) push(node *TraceNode) {
+	if len(t.stack) == 0 {
+		t.trace.Roots = append(t.trace.Roots, node)
+	} else {
+		parent := t.stack[len(t.stack)-1]
+		parent.Children = append(parent.Children, node)
+	}
+	t.stack = append(t.stack, node)
+}
+
+func (t *tracer) pop() {
+	t.stack = t.stack[:len(t.stack)-1]
+}
+
+// enter 开始元素e的求值：检查请求是否已取消，开启追踪时记录节点
+func (c *Context) enter(e Elem) (*TraceNode, error) {
+	if err := c.Context().Err(); err != nil {
+		return nil, err
+	}
+	if c.tracer == nil {
+		return nil, nil
+	}
+	node := &TraceNode{Kind: traceKind(e)}
+	c.tracer.push(node)
+	return node, nil
+}
+
+// leave 结束元素的求值记录，记录生成的sql或错误
+func (c *Context) leave(node *TraceNode, statement *Statement, err error) {
+	if node == nil {
+		return
+	}
+	c.tracer.pop()
+	if err != nil {
+		node.Error = err.Error()
+		return
+	}
+	if statement != nil {
+		node.Stmt = statement.Stmt
+		node.ArgNames = statement.ArgNames
+	}
+}
+
+// skip 记录一个条件不满足、未被求值的元素
+func (c *Context) skip(e ConditionElem) {
+	if c.tracer == nil {
+		return
+	}
+	node := &TraceNode{Kind: traceKind(e)}
+	if s, ok := e.(*_if); ok {
+		node.condition(s.Condition, false)
+	}
+	c.tracer.push(node)
+	c.tracer.pop()
+}
+
+func (n *TraceNode) condition(cond Condition, result bool) {
+	if n == nil {
+		return
+	}
+	n.Condition = conditionString(cond)
+	n.Result = &result
+}
+
+func (n *TraceNode) include(id string, props Parameters) {
+	if n == nil {
+		return
+	}
+	n.IncludeID = id
+	n.Props = MapParameters{}
+	for _, k := range props.Keys() {
+		n.Props[k] = props.Get(k)
+	}
+}
+
+func traceKind(e Elem) string {
+	switch e.(type) {
+	case *pure, *fragment:
+		return "frag"
+	case *_include:
+		return "include"
+	case *_if:
+		return "if"
+	case *choose:
+		return "choose"
+	case *trim:
+		return "trim"
+	case *composite:
+		return "composite"
+	case *dialectVariants:
+		return "variants"
+	case *paginate:
+		return "paginate"
+	case *count:
+		return "count"
+	case *seekPredicate, *seekOrder, *seekLimit:
+		return "seek"
+	case *upsert:
+		return "upsert"
+	case *column:
+		return "column"
+	case *versionSet, *versionWhere, *versionUpdate:
+		return "version"
+	case *orderBy:
+		return "orderBy"
+	case *like:
+		return "like"
+	case *script:
+		return "script"
+	}
+	return fmt.Sprintf("%T", e)
+}
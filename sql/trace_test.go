@@ -0,0 +1,65 @@
+package sql
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrace(t *testing.T) {
+	e := Composite(
+		`SELECT`,
+		Include("Image.SelectFields", false, MapParameters{
+			"alias":  "gi",
+			"digest": "$md5",
+		}),
+		`FROM gc_image gi`,
+		Where(
+			If(Test(".state"), `gi.state = #{state}`),
+			If(Test(".md5"), `AND gi.md5 = #{md5}`),
+		),
+	)
+	ctx := NewContext().
+		WithParams(MapParameters{"md5": "md5"}).
+		WithCollection(testCollection).
+		WithTrace()
+
+	_, err := e.Evaluate(ctx)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	trace := ctx.Trace()
+	if assert.Len(t, trace.Roots, 1) {
+		root := trace.Roots[0]
+		assert.Equal(t, "composite", root.Kind)
+		assert.Len(t, root.Children, 4)
+
+		include := root.Children[1]
+		assert.Equal(t, "Image.SelectFields", include.IncludeID)
+		assert.Equal(t, "md5", include.Props["digest"])
+		assert.Len(t, include.Children, 1)
+
+		where := root.Children[3]
+		if assert.Len(t, where.Children, 2) {
+			assert.Equal(t, ".state", where.Children[0].Condition)
+			assert.False(t, *where.Children[0].Result)
+			assert.Equal(t, ".md5", where.Children[1].Condition)
+			assert.True(t, *where.Children[1].Result)
+			assert.Equal(t, "AND gi.md5 = ?", where.Children[1].Stmt)
+		}
+		assert.Equal(t, "WHERE gi.md5 = ?", where.Stmt)
+	}
+
+	assert.Contains(t, trace.String(), "if [.state] => false")
+
+	b, err := trace.JSON()
+	if assert.NoError(t, err) {
+		var decoded Trace
+		assert.NoError(t, json.Unmarshal(b, &decoded))
+		assert.Equal(t, "composite", decoded.Roots[0].Kind)
+	}
+
+	assert.Nil(t, NewContext().Trace())
+}
@@ -0,0 +1,89 @@
+package sql
+
+import (
+	"reflect"
+
+	"github.com/non1996/go-batis/errors"
+)
+
+// TypeHandler 参数及结果的类型转换
+type TypeHandler interface {
+	// ToDB 将参数值转换为驱动可接受的值
+	ToDB(value any) (any, error)
+	// FromDB 将驱动返回的列值写入dest，dest 为目标字段的指针
+	FromDB(src any, dest any) error
+}
+
+// TypeHandlers 并发安全的类型处理器注册表，按参数/列名、Go类型查找处理器，另可按处理器名在片段中显式指定：
+//
+//	#{meta,handler=json}
+type TypeHandlers struct {
+	byName  cowMap[string, TypeHandler]       // 处理器名，用于 handler= 选项
+	byParam cowMap[string, TypeHandler]       // 参数名或列名
+	byType  cowMap[reflect.Type, TypeHandler] // Go类型
+}
+
+// DefaultTypeHandlers Context 未设置类型处理器时使用的注册表，预置处理器同 NewTypeHandlers
+var DefaultTypeHandlers = NewTypeHandlers()
+
+// NewTypeHandlers 创建注册表，预置 json、csv、uuid、time（UTC）、unixmillis 处理器
+func NewTypeHandlers() *TypeHandlers {
+	h := &TypeHandlers{}
+	h.byName.replace(map[string]TypeHandler{
+		"json":       JSON(),
+		"csv":        CSV(","),
+		"uuid":       UUIDBytes(),
+		"time":       Time(nil),
+		"unixmillis": UnixMillis(),
+	})
+	return h
+}
+
+// Define 注册具名处理器，供 #{x,handler=name} 引用
+func (h *TypeHandlers) Define(name string, handler TypeHandler) {
+	h.byName.set(name, handler)
+}
+
+// RegisterParam 注册参数名或列名对应的处理器
+func (h *TypeHandlers) RegisterParam(name string, handler TypeHandler) {
+	h.byParam.set(name, handler)
+}
+
+// RegisterType 注册Go类型对应的处理器
+func (h *TypeHandlers) RegisterType(typ reflect.Type, handler TypeHandler) {
+	h.byType.set(typ, handler)
+}
+
+// Lookup 查找处理器，依次按显式指定的处理器名、参数/列名、Go类型查找，
+// 显式指定的处理器不存在时返回错误
+func (h *TypeHandlers) Lookup(handler string, name string, typ reflect.Type) (TypeHandler, bool, error) {
+	if handler != "" {
+		th, exist := h.byName.get(handler)
+		if !exist {
+			return nil, false, errors.MissingTypeHandler(handler)
+		}
+		return th, true, nil
+	}
+	if th, exist := h.byParam.get(name); exist {
+		return th, true, nil
+	}
+	if typ != nil {
+		if th, exist := h.byType.get(typ); exist {
+			return th, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// ToDB 按 Lookup 查找处理器并转换参数值，没有处理器时原样返回
+func (h *TypeHandlers) ToDB(handler string, name string, value any) (any, error) {
+	th, exist, err := h.Lookup(handler, name, reflect.TypeOf(value))
+	if err != nil || !exist {
+		return value, err
+	}
+	converted, err := th.ToDB(value)
+	if err != nil {
+		return nil, errors.TypeConvert(name, value, err)
+	}
+	return converted, nil
+}
@@ -0,0 +1,87 @@
+package sql
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testState string
+
+func TestTypeHandlers(t *testing.T) {
+	handlers := NewTypeHandlers()
+	handlers.RegisterType(reflect.TypeOf(testState("")), Enum[testState]("on", "off"))
+	handlers.RegisterParam("tags", CSV(","))
+
+	e := Frag(`UPDATE gc_image SET meta = #{meta, handler=json}, tags = #{tags}, state = #{state}, uid = #{uid,handler=uuid} WHERE id IN (#{ids})`)
+	params := MapParameters{
+		"meta":  map[string]int{"w": 1},
+		"tags":  []string{"a", "b"},
+		"state": testState("on"),
+		"uid":   "00112233-4455-6677-8899-aabbccddeeff",
+		"ids":   []int{1, 2},
+	}
+	stmt, args, err := Prepare(NewContext().WithParams(params).WithTypeHandlers(handlers), e).Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `UPDATE gc_image SET meta = ?, tags = ?, state = ?, uid = ? WHERE id IN (?, ?)`, stmt)
+		assert.Equal(t, []any{`{"w":1}`, "a,b", "on",
+			[]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}, 1, 2}, args)
+	}
+
+	// 非法枚举值
+	params["state"] = testState("unknown")
+	_, _, err = Prepare(NewContext().WithParams(params).WithTypeHandlers(handlers), e).Prepare()
+	assert.Error(t, err)
+
+	// 未定义的处理器
+	_, _, err = Prepare(NewContext().WithParams(MapParameters{"x": 1}), Frag(`#{x,handler=nope}`)).Prepare()
+	assert.EqualError(t, err, "missing type handler: nope")
+
+	// 参数清单及具名模式使用参数名
+	set, err := Collection{"x": e}.Parameters("x")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"meta", "tags", "state", "uid", "ids"}, set.Params())
+	}
+	stmt, _, err = Prepare(NewContext().Named(), Frag(`SET md5 = #{md5}`)).Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `SET md5 = :md5`, stmt)
+	}
+	// 具名模式下参数值由调用方传给驱动，无法转换
+	_, _, err = Prepare(NewContext().Named(), Frag(`SET meta = #{meta,handler=json}`)).Prepare()
+	assert.EqualError(t, err, "type handlers (meta) are not supported in named mode")
+	_, _, err = Prepare(NewContext().Named().WithTypeHandlers(handlers), Frag(`SET tags = #{tags}`)).Prepare()
+	assert.EqualError(t, err, "type handlers (tags) are not supported in named mode")
+}
+
+func TestBuiltinTypeHandlers(t *testing.T) {
+	var meta map[string]int
+	assert.NoError(t, JSON().FromDB([]byte(`{"w":1}`), &meta))
+	assert.Equal(t, map[string]int{"w": 1}, meta)
+
+	var ids []int64
+	assert.NoError(t, CSV(",").FromDB("1,2,3", &ids))
+	assert.Equal(t, []int64{1, 2, 3}, ids)
+
+	var uid string
+	b, err := UUIDBytes().ToDB("00112233-4455-6677-8899-aabbccddeeff")
+	if assert.NoError(t, err) {
+		assert.NoError(t, UUIDBytes().FromDB(b, &uid))
+		assert.Equal(t, "00112233-4455-6677-8899-aabbccddeeff", uid)
+	}
+
+	shanghai := time.FixedZone("CST", 8*3600)
+	var ts time.Time
+	assert.NoError(t, Time(shanghai).FromDB([]byte("2024-01-02 03:04:05"), &ts))
+	assert.Equal(t, time.Date(2024, 1, 1, 19, 4, 5, 0, time.UTC), ts.UTC())
+	v, err := Time(nil).ToDB(ts)
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.UTC, v.(time.Time).Location())
+	}
+
+	var state testState
+	assert.NoError(t, Enum[testState]("on", "off").FromDB([]byte("off"), &state))
+	assert.Equal(t, testState("off"), state)
+	assert.Error(t, Enum[testState]("on", "off").FromDB("unknown", &state))
+}
@@ -0,0 +1,227 @@
+package sql
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/non1996/go-batis/errors"
+)
+
+// Column 插入/更新的列，value 为值的sql片段，如 #{name}、NOW()，字符串按 Frag 处理
+func Column(name string, value any) ConditionElem {
+	return ColumnIf(True(), name, value)
+}
+
+// ColumnIf 满足条件时才插入/更新的列，类似 Set 中的 If
+func ColumnIf(condition Condition, name string, value any) ConditionElem {
+	return &column{
+		Condition: condition,
+		Name:      name,
+		Value:     anySliceToElemSlice([]any{value})[0],
+	}
+}
+
+// Upsert 按方言生成插入或更新语句，key 为冲突判断的唯一键列，update 为冲突时更新的列（为nil时更新除唯一键外的所有列），
+// 未满足条件的列不插入也不更新：
+//
+//	MySQL:             INSERT INTO t (...) VALUES (...) ON DUPLICATE KEY UPDATE c = VALUES(c)
+//	PostgreSQL/SQLite: INSERT INTO t (...) VALUES (...) ON CONFLICT (k) DO UPDATE SET c = EXCLUDED.c
+//	SQL Server/Oracle: MERGE INTO t USING (...) s ON (t.k = s.k) WHEN MATCHED THEN UPDATE ... WHEN NOT MATCHED THEN INSERT ...
+func Upsert(table string, key []string, update []string, columns ...ConditionElem) Elem {
+	return &upsert{
+		Table:   table,
+		Key:     key,
+		Update:  update,
+		Columns: columns,
+	}
+}
+
+// column 插入/更新的列
+type column struct {
+	Condition
+	Name  string
+	Value Elem
+}
+
+func (s *column) Evaluate(ctx *Context) (statement *Statement, err error) {
+	node, err := ctx.enter(s)
+	defer func() { ctx.leave(node, statement, err) }()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Value.Evaluate(ctx)
+}
+
+type upsert struct {
+	Table   string
+	Key     []string
+	Update  []string
+	Columns []ConditionElem
+}
+
+func (s *upsert) Evaluate(ctx *Context) (statement *Statement, err error) {
+	node, err := ctx.enter(s)
+	defer func() { ctx.leave(node, statement, err) }()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(s.Key) == 0 {
+		return nil, errors.UnsupportedElement("upsert " + s.Table + ": empty key")
+	}
+
+	var names, values, updates []string
+	var columns []*Statement // 各列求值结果，参数及推导出的参数按列顺序合并
+	for _, child := range s.Columns {
+		col, ok := child.(*column)
+		if !ok {
+			return nil, errors.UnsupportedElement(fmt.Sprintf("upsert: %T", child))
+		}
+		satisfy, err := col.Satisfy(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !satisfy {
+			ctx.skip(col)
+			continue
+		}
+		value, err := col.Evaluate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, col.Name)
+		values = append(values, value.Stmt)
+		columns = append(columns, value)
+		if s.updated(col.Name) {
+			updates = append(updates, col.Name)
+		}
+	}
+	for _, k := range s.Key {
+		if !slices.Contains(names, k) {
+			return nil, errors.MissingUpsertKey(s.Table, k)
+		}
+	}
+
+	var stmt string
+	switch ctx.dialect {
+	case PostgreSQL, SQLite:
+		stmt = s.onConflict(names, values, updates)
+	case SQLServer, Oracle:
+		stmt = s.merge(ctx.dialect, names, values, updates)
+	default:
+		stmt = s.onDuplicateKey(names, values, updates)
+	}
+	statement = StatementMerge(columns)
+	statement.Stmt = stmt
+	return statement, nil
+}
+
+// updated 冲突时是否更新该列
+func (s *upsert) updated(name string) bool {
+	if s.Update != nil {
+		return slices.Contains(s.Update, name)
+	}
+	return !slices.Contains(s.Key, name)
+}
+
+func (s *upsert) insert(names, values []string) string {
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", s.Table, strings.Join(names, ", "), strings.Join(values, ", "))
+}
+
+// onDuplicateKey 不更新任何列时以 k = k 作为空更新，不使用 INSERT IGNORE，后者会将唯一键冲突以外的错误也降为警告
+func (s *upsert) onDuplicateKey(names, values, updates []string) string {
+	if len(updates) == 0 {
+		return s.insert(names, values) + fmt.Sprintf(" ON DUPLICATE KEY UPDATE %s = %s", s.Key[0], s.Key[0])
+	}
+	sets := make([]string, 0, len(updates))
+	for _, u := range updates {
+		sets = append(sets, fmt.Sprintf("%s = VALUES(%s)", u, u))
+	}
+	return s.insert(names, values) + " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+}
+
+func (s *upsert) onConflict(names, values, updates []string) string {
+	stmt := s.insert(names, values) + " ON CONFLICT (" + strings.Join(s.Key, ", ") + ")"
+	if len(updates) == 0 {
+		return stmt + " DO NOTHING"
+	}
+	sets := make([]string, 0, len(updates))
+	for _, u := range updates {
+		sets = append(sets, fmt.Sprintf("%s = EXCLUDED.%s", u, u))
+	}
+	return stmt + " DO UPDATE SET " + strings.Join(sets, ", ")
+}
+
+func (s *upsert) merge(dialect Dialect, names, values, updates []string) string {
+	var b strings.Builder
+	b.WriteString("MERGE INTO " + s.Table + " t USING ")
+	if dialect == Oracle {
+		selects := make([]string, 0, len(names))
+		for i, name := range names {
+			selects = append(selects, values[i]+" AS "+name)
+		}
+		b.WriteString("(SELECT " + strings.Join(selects, ", ") + " FROM dual) s")
+	} else {
+		b.WriteString("(VALUES (" + strings.Join(values, ", ") + ")) s (" + strings.Join(names, ", ") + ")")
+	}
+
+	on := make([]string, 0, len(s.Key))
+	for _, k := range s.Key {
+		on = append(on, fmt.Sprintf("t.%s = s.%s", k, k))
+	}
+	b.WriteString(" ON (" + strings.Join(on, " AND ") + ")")
+
+	if len(updates) != 0 {
+		sets := make([]string, 0, len(updates))
+		for _, u := range updates {
+			sets = append(sets, fmt.Sprintf("t.%s = s.%s", u, u))
+		}
+		b.WriteString(" WHEN MATCHED THEN UPDATE SET " + strings.Join(sets, ", "))
+	}
+
+	sources := make([]string, 0, len(names))
+	for _, name := range names {
+		sources = append(sources, "s."+name)
+	}
+	b.WriteString(" WHEN NOT MATCHED THEN INSERT (" + strings.Join(names, ", ") + ") VALUES (" + strings.Join(sources, ", ") + ")")
+	if dialect == SQLServer {
+		b.WriteString(";")
+	}
+	return b.String()
+}
+
+func (s *column) inspect(w *walker) error {
+	w.test(s.Condition)
+	return w.guarded(guards(s.Condition), func() error {
+		return w.walk(s.Value)
+	})
+}
+
+func (s *upsert) inspect(w *walker) error {
+	return w.walkAll(elems(s.Columns))
+}
+
+func (s *column) goSource() (string, error) {
+	value, err := GoSource(s.Value)
+	if err != nil {
+		return "", err
+	}
+	if s.Condition == tc {
+		return fmt.Sprintf("sql.Column(%s, %s)", goString(s.Name), value), nil
+	}
+	cond, err := goCondition(s.Condition)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sql.ColumnIf(%s, %s, %s)", cond, goString(s.Name), value), nil
+}
+
+func (s *upsert) goSource() (string, error) {
+	columns, err := goSourceAll(elems(s.Columns))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sql.Upsert(%s, %s, %s,%s)", goString(s.Table), goStrings(s.Key), goStrings(s.Update), columns), nil
+}
@@ -0,0 +1,77 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpsert(t *testing.T) {
+	e := Upsert("gc_image", []string{"md5"}, nil,
+		Column("md5", "#{md5}"),
+		ColumnIf(Test(".name"), "name", "#{name}"),
+		Column("updated_at", "NOW()"),
+	)
+	params := MapParameters{"md5": "abc", "name": "cat"}
+	render := func(dialect Dialect, params Parameters) (string, []any) {
+		stmt, args, err := Prepare(NewContext().WithParams(params).WithDialect(dialect), e).Prepare()
+		assert.NoError(t, err)
+		return stmt, args
+	}
+
+	stmt, args := render(MySQL, params)
+	assert.Equal(t, `INSERT INTO gc_image (md5, name, updated_at) VALUES (?, ?, NOW()) ON DUPLICATE KEY UPDATE name = VALUES(name), updated_at = VALUES(updated_at)`, stmt)
+	assert.Equal(t, []any{"abc", "cat"}, args)
+
+	stmt, _ = render(PostgreSQL, params)
+	assert.Equal(t, `INSERT INTO gc_image (md5, name, updated_at) VALUES ($1, $2, NOW()) ON CONFLICT (md5) DO UPDATE SET name = EXCLUDED.name, updated_at = EXCLUDED.updated_at`, stmt)
+
+	// 未满足条件的列不插入也不更新
+	stmt, args = render(SQLite, MapParameters{"md5": "abc", "name": ""})
+	assert.Equal(t, `INSERT INTO gc_image (md5, updated_at) VALUES (?, NOW()) ON CONFLICT (md5) DO UPDATE SET updated_at = EXCLUDED.updated_at`, stmt)
+	assert.Equal(t, []any{"abc"}, args)
+
+	stmt, _ = render(SQLServer, params)
+	assert.Equal(t, `MERGE INTO gc_image t USING (VALUES (@p1, @p2, NOW())) s (md5, name, updated_at) ON (t.md5 = s.md5)`+
+		` WHEN MATCHED THEN UPDATE SET t.name = s.name, t.updated_at = s.updated_at`+
+		` WHEN NOT MATCHED THEN INSERT (md5, name, updated_at) VALUES (s.md5, s.name, s.updated_at);`, stmt)
+
+	stmt, _ = render(Oracle, params)
+	assert.Equal(t, `MERGE INTO gc_image t USING (SELECT :1 AS md5, :2 AS name, NOW() AS updated_at FROM dual) s ON (t.md5 = s.md5)`+
+		` WHEN MATCHED THEN UPDATE SET t.name = s.name, t.updated_at = s.updated_at`+
+		` WHEN NOT MATCHED THEN INSERT (md5, name, updated_at) VALUES (s.md5, s.name, s.updated_at)`, stmt)
+
+	// 不更新任何列
+	ignore := Upsert("gc_image", []string{"md5"}, []string{}, Column("md5", "#{md5}"))
+	stmt, _, err := Prepare(NewContext().WithParams(params).WithDialect(MySQL), ignore).Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `INSERT INTO gc_image (md5) VALUES (?) ON DUPLICATE KEY UPDATE md5 = md5`, stmt)
+	}
+
+	// 唯一键列缺失或未满足条件
+	missing := Upsert("gc_image", []string{"md5"}, nil, ColumnIf(Test(".md5"), "md5", "#{md5}"), Column("name", "#{name}"))
+	_, _, err = Prepare(NewContext().WithParams(MapParameters{"md5": "", "name": "cat"}), missing).Prepare()
+	assert.EqualError(t, err, "upsert gc_image: missing key column md5")
+
+	// 列值中推导出的参数
+	derived := Upsert("gc_image", []string{"md5"}, nil,
+		Column("md5", "#{md5}"),
+		Column("tenant", Script(func(ctx *Context) (*Statement, error) {
+			statement := &Statement{}
+			ctx.Bind(statement, int64(7))
+			statement.Stmt = "?"
+			return statement, nil
+		})),
+		Column("matched", Contains("name", "name")),
+	)
+	stmt, args, err = Prepare(NewContext().WithParams(params).WithDialect(PostgreSQL), derived).Prepare()
+	if assert.NoError(t, err) {
+		assert.Contains(t, stmt, `(md5, tenant, matched) VALUES ($1, $2, `)
+		assert.Equal(t, []any{"abc", int64(7), "%cat%"}, args)
+	}
+
+	src, err := GoSource(e)
+	if assert.NoError(t, err) {
+		assert.Contains(t, src, `sql.ColumnIf(sql.Test(".name"), "name", sql.Frag("#{name}"))`)
+	}
+}
@@ -0,0 +1,222 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/non1996/go-batis/errors"
+)
+
+// OptimisticVersion 乐观锁版本号列，更新时按版本号匹配并递增版本号
+//
+//	version := sql.Version("version", "version")
+//	version.Update(sql.Frag(`UPDATE t SET name = #{name} WHERE id = #{id}`))
+//	// 或在动态语句中使用元素
+//	sql.Composite(`UPDATE t`, sql.Set(`name = #{name},`, version.Set()), sql.Where(sql.Frag(`id = #{id}`), version.Where()))
+type OptimisticVersion struct {
+	Column string // 版本号列名
+	Param  string // 当前版本号的参数名
+}
+
+// Version 乐观锁版本号列，param 为当前版本号的参数名
+func Version(column, param string) *OptimisticVersion {
+	return &OptimisticVersion{Column: column, Param: param}
+}
+
+// Set 递增版本号，在 Set 中使用，须为最后一项，前面的项以逗号结尾
+func (v *OptimisticVersion) Set() Elem {
+	return &versionSet{version: v}
+}
+
+// Update 在已有的更新语句上追加版本号的递增及匹配：SET 最后追加 col = col + 1，
+// WHERE 最后追加 AND col = #{param}（WHERE 中含顶层 OR 时原条件加括号，没有 WHERE 时添加）
+func (v *OptimisticVersion) Update(update Elem) Elem {
+	return &versionUpdate{version: v, Update: update}
+}
+
+// Where 匹配当前版本号，以 AND 开头，在 Where 中使用
+func (v *OptimisticVersion) Where() Elem {
+	return &versionWhere{version: v}
+}
+
+type versionSet struct {
+	version *OptimisticVersion
+}
+
+func (s *versionSet) Evaluate(ctx *Context) (statement *Statement, err error) {
+	node, err := ctx.enter(s)
+	defer func() { ctx.leave(node, statement, err) }()
+	if err != nil {
+		return nil, err
+	}
+
+	column := s.version.Column
+	return NewStatement(column+" = "+column+" + 1", nil), nil
+}
+
+type versionUpdate struct {
+	version *OptimisticVersion
+	Update  Elem
+}
+
+func (s *versionUpdate) Evaluate(ctx *Context) (statement *Statement, err error) {
+	node, err := ctx.enter(s)
+	defer func() { ctx.leave(node, statement, err) }()
+	if err != nil {
+		return nil, err
+	}
+
+	update, err := s.Update.Evaluate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mark := "?"
+	if ctx.named {
+		mark = ":" + s.version.Param
+	}
+	return s.version.rewrite(update, mark)
+}
+
+// versionTail 更新语句中 WHERE 之后的顶层子句
+var versionTail = []string{"ORDER", "LIMIT", "RETURNING"}
+
+// rewrite 按顶层关键字定位 SET 列表及 WHERE 条件的末尾，插入版本号的递增及匹配，参数插入到对应位置
+func (v *OptimisticVersion) rewrite(update *Statement, mark string) (*Statement, error) {
+	tokens := tokenize(update.Stmt)
+	set, setEnd, where, end := -1, -1, -1, len(tokens)
+	depth, or := 0, false
+	for i, t := range tokens {
+		switch {
+		case t.is(tokPunct, "("):
+			depth++
+		case t.is(tokPunct, ")"):
+			depth--
+		case t.kind != tokWord || depth != 0 || end != len(tokens):
+		case set < 0:
+			if t.upper() == "SET" {
+				set = i
+			}
+		case setEnd < 0 && (t.upper() == "FROM" || t.upper() == "OUTPUT" || t.upper() == "WHERE"):
+			setEnd = i
+			if t.upper() == "WHERE" {
+				where = i
+			}
+		case where < 0 && t.upper() == "WHERE":
+			where = i
+		case where >= 0 && t.upper() == "OR":
+			or = true
+		case containsFold(versionTail, t.upper()):
+			end = i
+		}
+	}
+	if set < 0 {
+		return nil, errors.UnsupportedStatement(update.Stmt, "missing SET")
+	}
+	if setEnd < 0 {
+		setEnd = end
+	}
+
+	// 插入点前移到空白之前
+	trim := func(i int) int {
+		for i > 0 && (tokens[i-1].kind == tokSpace || tokens[i-1].kind == tokComment) {
+			i--
+		}
+		return i
+	}
+	inserts := map[int]string{trim(setEnd): ", " + v.Column + " = " + v.Column + " + 1"}
+	if where < 0 {
+		inserts[trim(end)] += " WHERE " + v.Column + " = " + mark
+	} else {
+		match := " AND " + v.Column + " = " + mark
+		if or {
+			inserts[where+1] = " ("
+			match = ")" + match
+		}
+		inserts[trim(end)] += match
+	}
+
+	var b strings.Builder
+	argNames := make([]string, 0, len(update.ArgNames)+1)
+	idx := 0
+	for i := 0; i <= len(tokens); i++ {
+		if text, exist := inserts[i]; exist {
+			if i == trim(end) {
+				argNames = append(argNames, v.Param)
+			}
+			b.WriteString(text)
+		}
+		if i == len(tokens) {
+			break
+		}
+		t := tokens[i]
+		if t.kind == tokPlaceholder && idx < len(update.ArgNames) {
+			argNames = append(argNames, update.ArgNames[idx])
+			idx++
+		}
+		if i == where+1 && or && t.kind == tokSpace {
+			continue // 括号前已有空格
+		}
+		b.WriteString(t.text)
+	}
+	return &Statement{Stmt: b.String(), ArgNames: argNames, Bindings: update.Bindings}, nil
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+type versionWhere struct {
+	version *OptimisticVersion
+}
+
+func (s *versionWhere) Evaluate(ctx *Context) (statement *Statement, err error) {
+	node, err := ctx.enter(s)
+	defer func() { ctx.leave(node, statement, err) }()
+	if err != nil {
+		return nil, err
+	}
+
+	return Frag("AND " + s.version.Column + " = #{" + s.version.Param + "}").Evaluate(ctx)
+}
+
+func (s *versionSet) inspect(w *walker) error {
+	return nil
+}
+
+func (s *versionUpdate) inspect(w *walker) error {
+	if err := w.walk(s.Update); err != nil {
+		return err
+	}
+	w.param(s.version.Param)
+	return nil
+}
+
+func (s *versionWhere) inspect(w *walker) error {
+	w.param(s.version.Param)
+	return nil
+}
+
+func (v *OptimisticVersion) goSource() string {
+	return fmt.Sprintf("sql.Version(%q, %q)", v.Column, v.Param)
+}
+
+func (s *versionSet) goSource() (string, error) {
+	return s.version.goSource() + ".Set()", nil
+}
+
+func (s *versionWhere) goSource() (string, error) {
+	return s.version.goSource() + ".Where()", nil
+}
+
+func (s *versionUpdate) goSource() (string, error) {
+	update, err := GoSource(s.Update)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s.Update(%s)", s.version.goSource(), update), nil
+}
@@ -0,0 +1,74 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersion(t *testing.T) {
+	version := Version("version", "version")
+	e := Composite(`UPDATE gc_image`,
+		Set(If(Test(".name"), `name = #{name},`), version.Set()),
+		Where(Frag(`id = #{id}`), version.Where()),
+	)
+
+	stmt, args, err := Prepare(NewContext().WithParams(MapParameters{"id": 1, "name": "cat", "version": int64(3)}), e).Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `UPDATE gc_image SET name = ?, version = version + 1 WHERE id = ? AND version = ?`, stmt)
+		assert.Equal(t, []any{"cat", 1, int64(3)}, args)
+	}
+
+	stmt, _, err = Prepare(NewContext().WithParams(MapParameters{"id": 1, "name": "", "version": int64(3)}), e).Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `UPDATE gc_image SET version = version + 1 WHERE id = ? AND version = ?`, stmt)
+	}
+
+	// Set 中只有版本号
+	stmt, _, err = Prepare(NewContext().WithParams(MapParameters{"id": 1, "version": int64(3)}), Composite(`UPDATE gc_image`, Set(version.Set()), Where(Frag(`id = #{id}`), version.Where()))).Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `UPDATE gc_image SET version = version + 1 WHERE id = ? AND version = ?`, stmt)
+	}
+
+	set, err := Collection{"Image.Update": e}.Parameters("Image.Update")
+	if assert.NoError(t, err) {
+		assert.Contains(t, set.Params(), "version")
+	}
+}
+
+func TestVersionUpdate(t *testing.T) {
+	version := Version("version", "version")
+	params := MapParameters{"id": 1, "name": "cat", "state": 2, "version": int64(3), "limit": 10}
+	render := func(update string) (string, []any) {
+		stmt, args, err := Prepare(NewContext().WithParams(params), version.Update(Frag(update))).Prepare()
+		assert.NoError(t, err)
+		return stmt, args
+	}
+
+	stmt, args := render(`UPDATE gc_image SET name = #{name} WHERE id = #{id}`)
+	assert.Equal(t, `UPDATE gc_image SET name = ?, version = version + 1 WHERE id = ? AND version = ?`, stmt)
+	assert.Equal(t, []any{"cat", 1, int64(3)}, args)
+
+	// 顶层 OR 加括号，参数插入到尾部子句之前
+	stmt, args = render(`UPDATE gc_image SET name = #{name} WHERE id = #{id} OR (state = #{state} AND 1 = 1) ORDER BY id LIMIT #{limit}`)
+	assert.Equal(t, `UPDATE gc_image SET name = ?, version = version + 1 WHERE (id = ? OR (state = ? AND 1 = 1)) AND version = ? ORDER BY id LIMIT ?`, stmt)
+	assert.Equal(t, []any{"cat", 1, 2, int64(3), 10}, args)
+
+	// 没有 WHERE，SET 中的子查询不影响定位
+	stmt, _ = render(`UPDATE gc_image SET name = (SELECT name FROM gc_user WHERE id = #{id})`)
+	assert.Equal(t, `UPDATE gc_image SET name = (SELECT name FROM gc_user WHERE id = ?), version = version + 1 WHERE version = ?`, stmt)
+
+	// 具名模式
+	stmt, _, err := Prepare(NewContext().Named(), version.Update(Frag(`UPDATE gc_image SET name = #{name} WHERE id = #{id}`))).Prepare()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `UPDATE gc_image SET name = :name, version = version + 1 WHERE id = :id AND version = :version`, stmt)
+	}
+
+	_, _, err = Prepare(NewContext(), version.Update(Frag(`DELETE FROM gc_image`))).Prepare()
+	assert.Error(t, err)
+
+	src, err := GoSource(version.Update(Frag(`UPDATE t SET a = 1`)))
+	if assert.NoError(t, err) {
+		assert.Equal(t, `sql.Version("version", "version").Update(sql.Frag("UPDATE t SET a = 1"))`, src)
+	}
+}